@@ -0,0 +1,130 @@
+package cloudfoundry
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"github.com/terraform-providers/terraform-provider-cloudfoundry/cloudfoundry/managers"
+	"github.com/terraform-providers/terraform-provider-cloudfoundry/cloudfoundry/managers/bits"
+)
+
+func dataSourceAppDroplet() *schema.Resource {
+
+	return &schema.Resource{
+
+		ReadContext: dataSourceAppDropletRead,
+
+		Schema: map[string]*schema.Schema{
+
+			"app": &schema.Schema{
+				Type:         schema.TypeString,
+				Required:     true,
+				ValidateFunc: validation.NoZeroValues,
+			},
+			"path": &schema.Schema{
+				Type:         schema.TypeString,
+				Required:     true,
+				Description:  "Local path where current droplet of app is exported",
+				ValidateFunc: validation.NoZeroValues,
+			},
+			"checksum": &schema.Schema{
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"stack": &schema.Schema{
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"buildpacks": &schema.Schema{
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+			"created_at": &schema.Schema{
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+		},
+	}
+}
+
+func dataSourceAppDropletRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	session := meta.(*managers.Session)
+
+	appGUID := d.Get("app").(string)
+	droplet, err := session.BitsManager.GetAppDropletCurrent(appGUID)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	if droplet.State != "STAGED" {
+		return diag.Errorf("Current droplet %s of app %s is in state %s and can't be exported", droplet.GUID, appGUID, droplet.State)
+	}
+
+	path := d.Get("path").(string)
+	checksum, err := fileSha256(path)
+	if err != nil || droplet.Checksum.Type != "sha256" || checksum != droplet.Checksum.Value {
+		log.Printf("[INFO] Exporting droplet %s of app %s to %s", droplet.GUID, appGUID, path)
+		checksum, err = downloadDroplet(session.BitsManager, droplet, path)
+		if err != nil {
+			return diag.FromErr(err)
+		}
+	}
+
+	buildpacks := make([]string, 0, len(droplet.Buildpacks))
+	for _, bp := range droplet.Buildpacks {
+		buildpacks = append(buildpacks, bp.Name)
+	}
+	d.SetId(droplet.GUID)
+	d.Set("checksum", checksum)
+	d.Set("stack", droplet.Stack)
+	d.Set("buildpacks", buildpacks)
+	d.Set("created_at", droplet.CreatedAt)
+	return nil
+}
+
+// downloadDroplet - download droplet in a temporary file next to path and move it
+// to path only when download succeed, an existing export is never left half written
+func downloadDroplet(bitsManager *bits.BitsManager, droplet bits.Droplet, path string) (string, error) {
+	err := os.MkdirAll(filepath.Dir(path), 0755)
+	if err != nil {
+		return "", err
+	}
+	f, err := os.Create(path + ".tmp")
+	if err != nil {
+		return "", err
+	}
+	checksum, err := bitsManager.DownloadDroplet(droplet, f)
+	if err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return "", err
+	}
+	err = f.Close()
+	if err != nil {
+		os.Remove(f.Name())
+		return "", err
+	}
+	return checksum, os.Rename(f.Name(), path)
+}
+
+func fileSha256(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	h := sha256.New()
+	_, err = io.Copy(h, f)
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
@@ -0,0 +1,85 @@
+package cloudfoundry
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+const appDropletDataResource = `
+
+resource "cloudfoundry_app" "dummy-app-droplet" {
+  name = "dummy-app-droplet"
+  buildpack = "binary_buildpack"
+  space = "%s"
+  memory = "64"
+  disk_quota = "512"
+  timeout = 1800
+  path = "%s"
+}
+
+data "cloudfoundry_app_droplet" "d" {
+  app  = cloudfoundry_app.dummy-app-droplet.id
+  path = "%s"
+}
+`
+
+func TestAccDataSourceAppDroplet_normal(t *testing.T) {
+
+	spaceID, _ := defaultTestSpace(t)
+
+	exportDir, err := ioutil.TempDir("", "cf-droplet-export")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(exportDir)
+	exportPath := filepath.Join(exportDir, "droplet.tgz")
+
+	ref := "data.cloudfoundry_app_droplet.d"
+	resource.Test(t,
+		resource.TestCase{
+			PreCheck:          func() { testAccPreCheck(t) },
+			ProviderFactories: testAccProvidersFactories,
+			CheckDestroy:      testAccCheckAppDestroyed([]string{"dummy-app-droplet"}),
+			Steps: []resource.TestStep{
+
+				resource.TestStep{
+					Config: fmt.Sprintf(appDropletDataResource, spaceID, appPath, exportPath),
+					Check: resource.ComposeTestCheckFunc(
+						checkDataSourceAppDropletExported(ref, exportPath),
+						resource.TestCheckResourceAttr(ref, "buildpacks.0", "binary"),
+						resource.TestCheckResourceAttrSet(ref, "stack"),
+					),
+				},
+			},
+		})
+}
+
+func checkDataSourceAppDropletExported(resource string, path string) resource.TestCheckFunc {
+
+	return func(s *terraform.State) error {
+
+		rs, ok := s.RootModule().Resources[resource]
+		if !ok {
+			return fmt.Errorf("app droplet '%s' not found in terraform state", resource)
+		}
+
+		droplet, err := testSession().BitsManager.GetAppDropletCurrent(rs.Primary.Attributes["app"])
+		if err != nil {
+			return err
+		}
+		if err := assertSame(rs.Primary.ID, droplet.GUID); err != nil {
+			return err
+		}
+		checksum, err := fileSha256(path)
+		if err != nil {
+			return err
+		}
+		return assertSame(rs.Primary.Attributes["checksum"], checksum)
+	}
+}
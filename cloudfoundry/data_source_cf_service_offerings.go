@@ -0,0 +1,256 @@
+package cloudfoundry
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"github.com/terraform-providers/terraform-provider-cloudfoundry/cloudfoundry/hashcode"
+	"github.com/terraform-providers/terraform-provider-cloudfoundry/cloudfoundry/managers"
+)
+
+const (
+	marketplaceAvailable   = "available"
+	marketplaceUnavailable = "unavailable"
+	marketplaceAll         = "all"
+	marketplaceFree        = "free"
+	marketplacePaid        = "paid"
+)
+
+// marketplaceFiltersSchema - filters shared by marketplace data sources
+func marketplaceFiltersSchema() map[string]*schema.Schema {
+	return map[string]*schema.Schema{
+		"names": &schema.Schema{
+			Type:     schema.TypeList,
+			Optional: true,
+			Elem:     &schema.Schema{Type: schema.TypeString},
+		},
+		"service_broker": &schema.Schema{
+			Type:     schema.TypeString,
+			Optional: true,
+		},
+		"space": &schema.Schema{
+			Type:        schema.TypeString,
+			Optional:    true,
+			Description: "Only keep what is visible in this space, space scoped brokers included",
+		},
+		"availability": &schema.Schema{
+			Type:         schema.TypeString,
+			Optional:     true,
+			Default:      marketplaceAvailable,
+			ValidateFunc: validation.StringInSlice([]string{marketplaceAvailable, marketplaceUnavailable, marketplaceAll}, false),
+		},
+		"label_selector": &schema.Schema{
+			Type:     schema.TypeString,
+			Optional: true,
+		},
+		"cost": &schema.Schema{
+			Type:         schema.TypeString,
+			Optional:     true,
+			Default:      marketplaceAll,
+			ValidateFunc: validation.StringInSlice([]string{marketplaceFree, marketplacePaid, marketplaceAll}, false),
+		},
+	}
+}
+
+// marketplaceQuery - cloud controller v3 query made from marketplace filters, cost can only be filtered on client side
+func marketplaceQuery(d *schema.ResourceData) url.Values {
+	query := url.Values{}
+	names := make([]string, 0)
+	for _, n := range d.Get("names").([]interface{}) {
+		names = append(names, n.(string))
+	}
+	if len(names) > 0 {
+		query.Set("names", strings.Join(names, ","))
+	}
+	if broker := d.Get("service_broker").(string); broker != "" {
+		query.Set("service_broker_guids", broker)
+	}
+	if space := d.Get("space").(string); space != "" {
+		query.Set("space_guids", space)
+	}
+	switch d.Get("availability").(string) {
+	case marketplaceAvailable:
+		query.Set("available", "true")
+	case marketplaceUnavailable:
+		query.Set("available", "false")
+	}
+	if selector := d.Get("label_selector").(string); selector != "" {
+		query.Set("label_selector", selector)
+	}
+	return query
+}
+
+// marketplaceID - data source id derived from filters to stay stable between reads
+func marketplaceID(d *schema.ResourceData, query url.Values) string {
+	return strconv.Itoa(hashcode.String(query.Encode() + "&cost=" + d.Get("cost").(string)))
+}
+
+func v3LabelsToMap(labels map[string]*string) map[string]interface{} {
+	m := make(map[string]interface{})
+	for k, v := range labels {
+		if v != nil {
+			m[k] = *v
+		}
+	}
+	return m
+}
+
+func dataSourceServiceOfferings() *schema.Resource {
+
+	filters := marketplaceFiltersSchema()
+	filters["service_offerings"] = &schema.Schema{
+		Type:     schema.TypeList,
+		Computed: true,
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"id":                    &schema.Schema{Type: schema.TypeString, Computed: true},
+				"name":                  &schema.Schema{Type: schema.TypeString, Computed: true},
+				"description":           &schema.Schema{Type: schema.TypeString, Computed: true},
+				"available":             &schema.Schema{Type: schema.TypeBool, Computed: true},
+				"shareable":             &schema.Schema{Type: schema.TypeBool, Computed: true},
+				"bindable":              &schema.Schema{Type: schema.TypeBool, Computed: true},
+				"plan_updateable":       &schema.Schema{Type: schema.TypeBool, Computed: true},
+				"instances_retrievable": &schema.Schema{Type: schema.TypeBool, Computed: true},
+				"bindings_retrievable":  &schema.Schema{Type: schema.TypeBool, Computed: true},
+				"documentation_url":     &schema.Schema{Type: schema.TypeString, Computed: true},
+				"broker_catalog_id":     &schema.Schema{Type: schema.TypeString, Computed: true},
+				"broker_catalog_metadata": &schema.Schema{
+					Type:        schema.TypeString,
+					Computed:    true,
+					Description: "Metadata given by the broker in its catalog, as a json string",
+				},
+				"service_broker": &schema.Schema{Type: schema.TypeString, Computed: true},
+				"tags": &schema.Schema{
+					Type:     schema.TypeList,
+					Computed: true,
+					Elem:     &schema.Schema{Type: schema.TypeString},
+				},
+				"requires": &schema.Schema{
+					Type:     schema.TypeList,
+					Computed: true,
+					Elem:     &schema.Schema{Type: schema.TypeString},
+				},
+				"labels": &schema.Schema{
+					Type:     schema.TypeMap,
+					Computed: true,
+					Elem:     &schema.Schema{Type: schema.TypeString},
+				},
+			},
+		},
+	}
+
+	return &schema.Resource{
+
+		ReadContext: dataSourceServiceOfferingsRead,
+
+		Schema: filters,
+	}
+}
+
+// v3ServiceOffering - service offering as given by cloud controller v3 api
+type v3ServiceOffering struct {
+	GUID             string   `json:"guid"`
+	Name             string   `json:"name"`
+	Description      string   `json:"description"`
+	Available        bool     `json:"available"`
+	Shareable        bool     `json:"shareable"`
+	Tags             []string `json:"tags"`
+	Requires         []string `json:"requires"`
+	DocumentationURL string   `json:"documentation_url"`
+	BrokerCatalog    struct {
+		ID       string          `json:"id"`
+		Metadata json.RawMessage `json:"metadata"`
+		Features struct {
+			PlanUpdateable       bool `json:"plan_updateable"`
+			Bindable             bool `json:"bindable"`
+			InstancesRetrievable bool `json:"instances_retrievable"`
+			BindingsRetrievable  bool `json:"bindings_retrievable"`
+		} `json:"features"`
+	} `json:"broker_catalog"`
+	Relationships struct {
+		ServiceBroker *v3Relationship `json:"service_broker"`
+	} `json:"relationships"`
+	Metadata struct {
+		Labels map[string]*string `json:"labels"`
+	} `json:"metadata"`
+}
+
+func dataSourceServiceOfferingsRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	session := meta.(*managers.Session)
+
+	query := marketplaceQuery(d)
+
+	// offerings has no cost, an offering is kept when at least one of its plans has the cost asked
+	var offeringGUIDs map[string]bool
+	if cost := d.Get("cost").(string); cost != marketplaceAll {
+		planQuery := url.Values{}
+		for k, v := range query {
+			planQuery[k] = v
+		}
+		// labels selects offerings, not their plans
+		planQuery.Del("label_selector")
+		if names := planQuery.Get("names"); names != "" {
+			planQuery.Del("names")
+			planQuery.Set("service_offering_names", names)
+		}
+		plans, err := getV3ServicePlans(session, planQuery, cost)
+		if err != nil {
+			return diag.FromErr(err)
+		}
+		offeringGUIDs = make(map[string]bool)
+		for _, p := range plans {
+			offeringGUIDs[p.Relationships.ServiceOffering.GUID()] = true
+		}
+	}
+
+	offeringsTf := make([]map[string]interface{}, 0)
+	err := v3GetAll(session, "/v3/service_offerings?"+query.Encode(), func(resources json.RawMessage) error {
+		var offerings []v3ServiceOffering
+		err := json.Unmarshal(resources, &offerings)
+		if err != nil {
+			return err
+		}
+		for _, o := range offerings {
+			if offeringGUIDs != nil && !offeringGUIDs[o.GUID] {
+				continue
+			}
+			catalogMetadata := ""
+			if len(o.BrokerCatalog.Metadata) > 0 && string(o.BrokerCatalog.Metadata) != "null" {
+				catalogMetadata = string(o.BrokerCatalog.Metadata)
+			}
+			offeringsTf = append(offeringsTf, map[string]interface{}{
+				"id":                      o.GUID,
+				"name":                    o.Name,
+				"description":             o.Description,
+				"available":               o.Available,
+				"shareable":               o.Shareable,
+				"bindable":                o.BrokerCatalog.Features.Bindable,
+				"plan_updateable":         o.BrokerCatalog.Features.PlanUpdateable,
+				"instances_retrievable":   o.BrokerCatalog.Features.InstancesRetrievable,
+				"bindings_retrievable":    o.BrokerCatalog.Features.BindingsRetrievable,
+				"documentation_url":       o.DocumentationURL,
+				"broker_catalog_id":       o.BrokerCatalog.ID,
+				"broker_catalog_metadata": catalogMetadata,
+				"service_broker":          o.Relationships.ServiceBroker.GUID(),
+				"tags":                    o.Tags,
+				"requires":                o.Requires,
+				"labels":                  v3LabelsToMap(o.Metadata.Labels),
+			})
+		}
+		return nil
+	})
+	if err != nil {
+		return diag.FromErr(fmt.Errorf("Error while listing service offerings: %s", err))
+	}
+
+	d.SetId(marketplaceID(d, query))
+	d.Set("service_offerings", offeringsTf)
+	return nil
+}
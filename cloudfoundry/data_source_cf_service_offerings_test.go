@@ -0,0 +1,60 @@
+package cloudfoundry
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+const serviceOfferingsDataResource = `
+
+data "cloudfoundry_service" "test" {
+  name = "%s"
+}
+
+data "cloudfoundry_service_offerings" "test" {
+  names = ["%s"]
+}
+
+data "cloudfoundry_service_offerings" "free" {
+  names = ["%s"]
+  cost  = "free"
+}
+
+data "cloudfoundry_service_offerings" "none" {
+  names = ["%s"]
+  availability = "unavailable"
+}
+`
+
+func TestAccDataSourceServiceOfferings_normal(t *testing.T) {
+
+	serviceName1, _, _ := getTestServiceBrokers(t)
+
+	ref := "data.cloudfoundry_service_offerings.test"
+
+	resource.ParallelTest(t,
+		resource.TestCase{
+			PreCheck:          func() { testAccPreCheck(t) },
+			ProviderFactories: testAccProvidersFactories,
+			Steps: []resource.TestStep{
+
+				resource.TestStep{
+					Config: fmt.Sprintf(serviceOfferingsDataResource,
+						serviceName1, serviceName1, serviceName1, serviceName1),
+					Check: resource.ComposeTestCheckFunc(
+						resource.TestCheckResourceAttr(ref, "service_offerings.#", "1"),
+						resource.TestCheckResourceAttr(ref, "service_offerings.0.name", serviceName1),
+						resource.TestCheckResourceAttrPair(ref, "service_offerings.0.id",
+							"data.cloudfoundry_service.test", "id"),
+						resource.TestCheckResourceAttr(ref, "service_offerings.0.available", "true"),
+						resource.TestCheckResourceAttrSet(ref, "service_offerings.0.service_broker"),
+						resource.TestCheckResourceAttrSet(ref, "service_offerings.0.broker_catalog_id"),
+						resource.TestCheckResourceAttr("data.cloudfoundry_service_offerings.free", "service_offerings.#", "1"),
+						resource.TestCheckResourceAttr("data.cloudfoundry_service_offerings.none", "service_offerings.#", "0"),
+					),
+				},
+			},
+		})
+}
@@ -0,0 +1,189 @@
+package cloudfoundry
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/terraform-providers/terraform-provider-cloudfoundry/cloudfoundry/managers"
+)
+
+func dataSourceServicePlans() *schema.Resource {
+
+	filters := marketplaceFiltersSchema()
+	filters["service_offering"] = &schema.Schema{
+		Type:     schema.TypeString,
+		Optional: true,
+	}
+	filters["service_plans"] = &schema.Schema{
+		Type:     schema.TypeList,
+		Computed: true,
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"id":                           &schema.Schema{Type: schema.TypeString, Computed: true},
+				"name":                         &schema.Schema{Type: schema.TypeString, Computed: true},
+				"description":                  &schema.Schema{Type: schema.TypeString, Computed: true},
+				"available":                    &schema.Schema{Type: schema.TypeBool, Computed: true},
+				"free":                         &schema.Schema{Type: schema.TypeBool, Computed: true},
+				"bindable":                     &schema.Schema{Type: schema.TypeBool, Computed: true},
+				"plan_updateable":              &schema.Schema{Type: schema.TypeBool, Computed: true},
+				"visibility_type":              &schema.Schema{Type: schema.TypeString, Computed: true},
+				"broker_catalog_id":            &schema.Schema{Type: schema.TypeString, Computed: true},
+				"service_offering":             &schema.Schema{Type: schema.TypeString, Computed: true},
+				"maintenance_info_version":     &schema.Schema{Type: schema.TypeString, Computed: true},
+				"maintenance_info_description": &schema.Schema{Type: schema.TypeString, Computed: true},
+				"costs": &schema.Schema{
+					Type:     schema.TypeList,
+					Computed: true,
+					Elem: &schema.Resource{
+						Schema: map[string]*schema.Schema{
+							"amount":   &schema.Schema{Type: schema.TypeFloat, Computed: true},
+							"currency": &schema.Schema{Type: schema.TypeString, Computed: true},
+							"unit":     &schema.Schema{Type: schema.TypeString, Computed: true},
+						},
+					},
+				},
+				"schema_service_instance_create": &schema.Schema{
+					Type:        schema.TypeString,
+					Computed:    true,
+					Description: "Json schema of params for service instance creation",
+				},
+				"schema_service_instance_update": &schema.Schema{
+					Type:        schema.TypeString,
+					Computed:    true,
+					Description: "Json schema of params for service instance update",
+				},
+				"schema_service_binding_create": &schema.Schema{
+					Type:        schema.TypeString,
+					Computed:    true,
+					Description: "Json schema of params for service binding creation",
+				},
+				"labels": &schema.Schema{
+					Type:     schema.TypeMap,
+					Computed: true,
+					Elem:     &schema.Schema{Type: schema.TypeString},
+				},
+			},
+		},
+	}
+
+	return &schema.Resource{
+
+		ReadContext: dataSourceServicePlansRead,
+
+		Schema: filters,
+	}
+}
+
+// v3ServicePlan - service plan as given by cloud controller v3 api
+type v3ServicePlan struct {
+	GUID           string `json:"guid"`
+	Name           string `json:"name"`
+	Description    string `json:"description"`
+	Available      bool   `json:"available"`
+	Free           bool   `json:"free"`
+	VisibilityType string `json:"visibility_type"`
+	Costs          []struct {
+		Amount   float64 `json:"amount"`
+		Currency string  `json:"currency"`
+		Unit     string  `json:"unit"`
+	} `json:"costs"`
+	MaintenanceInfo struct {
+		Version     string `json:"version"`
+		Description string `json:"description"`
+	} `json:"maintenance_info"`
+	BrokerCatalog struct {
+		ID       string `json:"id"`
+		Features struct {
+			PlanUpdateable bool `json:"plan_updateable"`
+			Bindable       bool `json:"bindable"`
+		} `json:"features"`
+	} `json:"broker_catalog"`
+	Schemas       v3ServicePlanSchemas `json:"schemas"`
+	Relationships struct {
+		ServiceOffering *v3Relationship `json:"service_offering"`
+	} `json:"relationships"`
+	Metadata struct {
+		Labels map[string]*string `json:"labels"`
+	} `json:"metadata"`
+}
+
+// getV3ServicePlans - list plans matching query, cost is filtered on client side
+func getV3ServicePlans(session *managers.Session, query url.Values, cost string) ([]v3ServicePlan, error) {
+	plans := make([]v3ServicePlan, 0)
+	err := v3GetAll(session, "/v3/service_plans?"+query.Encode(), func(resources json.RawMessage) error {
+		var page []v3ServicePlan
+		err := json.Unmarshal(resources, &page)
+		if err != nil {
+			return err
+		}
+		for _, p := range page {
+			if (cost == marketplaceFree && !p.Free) || (cost == marketplacePaid && p.Free) {
+				continue
+			}
+			plans = append(plans, p)
+		}
+		return nil
+	})
+	return plans, err
+}
+
+// jsonSchemaToString - json schema as a string, absent schema gives an empty string
+func jsonSchemaToString(s map[string]interface{}) string {
+	if len(s) == 0 {
+		return ""
+	}
+	b, _ := json.Marshal(s)
+	return string(b)
+}
+
+func dataSourceServicePlansRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	session := meta.(*managers.Session)
+
+	query := marketplaceQuery(d)
+	if offering := d.Get("service_offering").(string); offering != "" {
+		query.Set("service_offering_guids", offering)
+	}
+	plans, err := getV3ServicePlans(session, query, d.Get("cost").(string))
+	if err != nil {
+		return diag.FromErr(fmt.Errorf("Error while listing service plans: %s", err))
+	}
+
+	plansTf := make([]map[string]interface{}, len(plans))
+	for i, p := range plans {
+		costs := make([]map[string]interface{}, len(p.Costs))
+		for j, c := range p.Costs {
+			costs[j] = map[string]interface{}{
+				"amount":   c.Amount,
+				"currency": c.Currency,
+				"unit":     c.Unit,
+			}
+		}
+		plansTf[i] = map[string]interface{}{
+			"id":                             p.GUID,
+			"name":                           p.Name,
+			"description":                    p.Description,
+			"available":                      p.Available,
+			"free":                           p.Free,
+			"bindable":                       p.BrokerCatalog.Features.Bindable,
+			"plan_updateable":                p.BrokerCatalog.Features.PlanUpdateable,
+			"visibility_type":                p.VisibilityType,
+			"broker_catalog_id":              p.BrokerCatalog.ID,
+			"service_offering":               p.Relationships.ServiceOffering.GUID(),
+			"maintenance_info_version":       p.MaintenanceInfo.Version,
+			"maintenance_info_description":   p.MaintenanceInfo.Description,
+			"costs":                          costs,
+			"schema_service_instance_create": jsonSchemaToString(p.Schemas.ServiceInstance.Create.Parameters),
+			"schema_service_instance_update": jsonSchemaToString(p.Schemas.ServiceInstance.Update.Parameters),
+			"schema_service_binding_create":  jsonSchemaToString(p.Schemas.ServiceBinding.Create.Parameters),
+			"labels":                         v3LabelsToMap(p.Metadata.Labels),
+		}
+	}
+
+	d.SetId(marketplaceID(d, query))
+	d.Set("service_plans", plansTf)
+	return nil
+}
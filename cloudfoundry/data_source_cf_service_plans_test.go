@@ -0,0 +1,55 @@
+package cloudfoundry
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+const servicePlansDataResource = `
+
+data "cloudfoundry_service" "test" {
+  name = "%s"
+}
+
+data "cloudfoundry_service_plans" "test" {
+  service_offering = data.cloudfoundry_service.test.id
+  names            = ["%s"]
+}
+
+data "cloudfoundry_service_plans" "paid" {
+  service_offering = data.cloudfoundry_service.test.id
+  cost             = "paid"
+}
+`
+
+func TestAccDataSourceServicePlans_normal(t *testing.T) {
+
+	serviceName1, _, servicePlan := getTestServiceBrokers(t)
+
+	ref := "data.cloudfoundry_service_plans.test"
+
+	resource.ParallelTest(t,
+		resource.TestCase{
+			PreCheck:          func() { testAccPreCheck(t) },
+			ProviderFactories: testAccProvidersFactories,
+			Steps: []resource.TestStep{
+
+				resource.TestStep{
+					Config: fmt.Sprintf(servicePlansDataResource,
+						serviceName1, servicePlan),
+					Check: resource.ComposeTestCheckFunc(
+						resource.TestCheckResourceAttr(ref, "service_plans.#", "1"),
+						resource.TestCheckResourceAttr(ref, "service_plans.0.name", servicePlan),
+						resource.TestCheckResourceAttrPair(ref, "service_plans.0.id",
+							"data.cloudfoundry_service.test", "service_plans."+servicePlan),
+						resource.TestCheckResourceAttrPair(ref, "service_plans.0.service_offering",
+							"data.cloudfoundry_service.test", "id"),
+						resource.TestCheckResourceAttr(ref, "service_plans.0.free", "true"),
+						resource.TestCheckResourceAttr("data.cloudfoundry_service_plans.paid", "service_plans.#", "0"),
+					),
+				},
+			},
+		})
+}
@@ -3,9 +3,22 @@ package cloudfoundry
 import (
 	"code.cloudfoundry.org/cli/api/cloudcontroller/ccv2"
 	"code.cloudfoundry.org/cli/api/cloudcontroller/ccv2/constant"
+	"encoding/json"
 	"fmt"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing/object"
 	"github.com/terraform-providers/terraform-provider-cloudfoundry/cloudfoundry/managers"
+	"github.com/terraform-providers/terraform-provider-cloudfoundry/cloudfoundry/managers/raw"
+	"io/ioutil"
 	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
 )
 
 type HelpersTest struct {
@@ -65,3 +78,149 @@ func (h HelpersTest) ForceDeleteServiceBroker(serviceBrokerID string) (err error
 	_, err = h.session.ClientV2.DeleteServiceBroker(serviceBrokerID)
 	return err
 }
+
+// testGitRepo - local bare git repository used to test git sources
+type testGitRepo struct {
+	BareDir string
+	workDir string
+	repo    *git.Repository
+}
+
+// newTestGitRepo - create a bare repository which will contain a copy of srcDir
+// inside a folder named after srcDir, nothing is committed until Commit is called
+func newTestGitRepo(t *testing.T, srcDir string) *testGitRepo {
+	tmpDir, err := ioutil.TempDir("", "provider-cf-git")
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := &testGitRepo{
+		BareDir: filepath.Join(tmpDir, "repo.git"),
+		workDir: filepath.Join(tmpDir, "work"),
+	}
+	err = filepath.Walk(srcDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+		rel, _ := filepath.Rel(filepath.Dir(srcDir), path)
+		b, err := ioutil.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		dest := filepath.Join(r.workDir, rel)
+		err = os.MkdirAll(filepath.Dir(dest), 0755)
+		if err != nil {
+			return err
+		}
+		return ioutil.WriteFile(dest, b, info.Mode())
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.repo, err = git.PlainInit(r.workDir, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = git.PlainInit(r.BareDir, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = r.repo.CreateRemote(&config.RemoteConfig{Name: "origin", URLs: []string{r.BareDir}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return r
+}
+
+// Commit - write given files, commit everything and push to bare repository, it returns commit sha
+func (r *testGitRepo) Commit(t *testing.T, files map[string]string) string {
+	for name, content := range files {
+		err := ioutil.WriteFile(filepath.Join(r.workDir, name), []byte(content), 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	wt, err := r.repo.Worktree()
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = wt.AddGlob(".")
+	if err != nil {
+		t.Fatal(err)
+	}
+	hash, err := wt.Commit("test commit", &git.CommitOptions{
+		Author: &object.Signature{Name: "test", Email: "test@example.com", When: time.Now()},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = r.repo.Push(&git.PushOptions{
+		RefSpecs: []config.RefSpec{"refs/heads/*:refs/heads/*"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return hash.String()
+}
+
+func (r *testGitRepo) Clean() {
+	os.RemoveAll(filepath.Dir(r.BareDir))
+}
+
+// fakeServer - in memory api served over http to unit test resources without cloud foundry,
+// requests are handled one at a time
+type fakeServer struct {
+	sync.Mutex
+	server *httptest.Server
+}
+
+// start - serve requests with handle, path of request is given split on slashes.
+// Info of v2 api is always served to let session target fake server
+func (f *fakeServer) start(handle func(w http.ResponseWriter, r *http.Request, parts []string)) {
+	f.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/v2/info" {
+			f.writeJSON(w, http.StatusOK, map[string]interface{}{})
+			return
+		}
+		f.Lock()
+		defer f.Unlock()
+		handle(w, r, strings.Split(strings.Trim(r.URL.Path, "/"), "/"))
+	}))
+}
+
+// session - session with cloud controller and uaa both on fake server
+func (f *fakeServer) session() *managers.Session {
+	client := raw.NewRawClient(raw.RawClientConfig{ApiEndpoint: f.server.URL})
+	clientV2 := ccv2.NewClient(ccv2.Config{AppName: "terraform-provider-cloudfoundry-test", AppVersion: "0", JobPollingTimeout: time.Minute})
+	if _, err := clientV2.TargetCF(ccv2.TargetSettings{URL: f.server.URL}); err != nil {
+		panic(err)
+	}
+	return &managers.Session{
+		ClientV2:     clientV2,
+		RawClient:    client,
+		RawClientUAA: client,
+	}
+}
+
+// readJSON - decode body of request, false when bad request has been answered
+func (f *fakeServer) readJSON(w http.ResponseWriter, r *http.Request, body interface{}) bool {
+	if err := json.NewDecoder(r.Body).Decode(body); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return false
+	}
+	return true
+}
+
+func (f *fakeServer) writeJSON(w http.ResponseWriter, status int, obj interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if obj != nil {
+		json.NewEncoder(w).Encode(obj)
+	}
+}
+
+// writeError - answer with an error as given by cloud controller v3 api
+func (f *fakeServer) writeError(w http.ResponseWriter, status int, detail string) {
+	f.writeJSON(w, status, map[string]interface{}{
+		"errors": []interface{}{map[string]interface{}{"detail": detail}},
+	})
+}
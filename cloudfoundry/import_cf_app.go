@@ -16,5 +16,12 @@ func resourceAppImport(ctx context.Context, d *schema.ResourceData, meta interfa
 	d.Set("path", fmt.Sprintf(dlImportPath, session.ApiEndpoint, d.Id()))
 	d.Set("timeout", DefaultAppTimeout)
 	d.Set("strategy", "none")
+	strategy, err := appRecordedStrategy(session, d.Id())
+	if err != nil && !IsErrNotFound(err) {
+		return nil, err
+	}
+	if !isDefaultStrategy(strategy) {
+		d.Set("strategy", strategy)
+	}
 	return ImportReadContext(resourceAppRead)(ctx, d, meta)
 }
@@ -21,9 +21,10 @@ func TestAccUserProvidedService_importBasic(t *testing.T) {
 				},
 
 				resource.TestStep{
-					ResourceName:      resourceName,
-					ImportState:       true,
-					ImportStateVerify: true,
+					ResourceName:            resourceName,
+					ImportState:             true,
+					ImportStateVerify:       true,
+					ImportStateVerifyIgnore: []string{"restage_bound_apps"},
 				},
 			},
 		})
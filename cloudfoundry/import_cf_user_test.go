@@ -27,6 +27,14 @@ func TestAccUser_WithGroups_importBasic(t *testing.T) {
 					ImportStateVerify:       true,
 					ImportStateVerifyIgnore: []string{"password"},
 				},
+
+				resource.TestStep{
+					ResourceName:            resourceName,
+					ImportState:             true,
+					ImportStateId:           "uaa/" + username,
+					ImportStateVerify:       true,
+					ImportStateVerifyIgnore: []string{"password"},
+				},
 			},
 		})
 }
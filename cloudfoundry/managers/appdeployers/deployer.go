@@ -35,6 +35,7 @@ func (d Deployer) Strategy(strategyName string) Strategy {
 func ValidStrategy(strategyName string) ([]string, bool) {
 	strategyName = strings.ToLower(strategyName)
 	names := append(Standard{}.Names(), BlueGreenV2{}.Names()...)
+	names = append(names, Rolling{}.Names()...)
 	for _, name := range names {
 		if name == strategyName {
 			return names, true
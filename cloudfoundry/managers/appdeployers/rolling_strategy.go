@@ -0,0 +1,238 @@
+package appdeployers
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"time"
+
+	"code.cloudfoundry.org/cli/api/cloudcontroller/ccerror"
+	"code.cloudfoundry.org/cli/api/cloudcontroller/ccv2"
+	"code.cloudfoundry.org/cli/api/cloudcontroller/ccv2/constant"
+	"code.cloudfoundry.org/cli/api/cloudcontroller/ccv3"
+	constantV3 "code.cloudfoundry.org/cli/api/cloudcontroller/ccv3/constant"
+	"github.com/terraform-providers/terraform-provider-cloudfoundry/cloudfoundry/common"
+	"github.com/terraform-providers/terraform-provider-cloudfoundry/cloudfoundry/managers/bits"
+	"github.com/terraform-providers/terraform-provider-cloudfoundry/cloudfoundry/managers/raw"
+)
+
+// Rolling - stage a new droplet and roll it out with a cloud controller deployment,
+// app keeps its GUID and instances are replaced one by one without interruption
+type Rolling struct {
+	bitsManager *bits.BitsManager
+	client      *ccv2.Client
+	clientV3    *ccv3.Client
+	rawClient   *raw.RawClient
+	runBinder   *RunBinder
+	standard    *Standard
+}
+
+// rollingDeployment - deployment as given by cloud controller, state is only given by older ones
+// while newer ones give status
+type rollingDeployment struct {
+	State  string `json:"state"`
+	Status struct {
+		Value  string `json:"value"`
+		Reason string `json:"reason"`
+	} `json:"status"`
+}
+
+func NewRolling(bitsManager *bits.BitsManager, client *ccv2.Client, clientV3 *ccv3.Client, rawClient *raw.RawClient, runBinder *RunBinder, standard *Standard) *Rolling {
+	return &Rolling{
+		bitsManager: bitsManager,
+		client:      client,
+		clientV3:    clientV3,
+		rawClient:   rawClient,
+		runBinder:   runBinder,
+		standard:    standard,
+	}
+}
+
+func (s Rolling) Deploy(appDeploy AppDeploy) (AppDeployResponse, error) {
+	if appDeploy.App.State == constant.ApplicationStopped || appDeploy.App.GUID == "" {
+		return s.standard.Deploy(appDeploy)
+	}
+	// keep app running during update, new settings are taken by the deployment
+	appUpdate := appDeploy.App
+	appUpdate.State = ""
+	app, _, err := s.client.UpdateApplication(appUpdate)
+	if err != nil {
+		return AppDeployResponse{}, err
+	}
+	appDeploy.App = app
+
+	mappings, err := s.runBinder.MapRoutes(appDeploy)
+	if err != nil {
+		return AppDeployResponse{}, err
+	}
+	bindings, err := s.runBinder.BindServiceInstances(appDeploy)
+	if err != nil {
+		return AppDeployResponse{}, err
+	}
+	if appDeploy.Path != "" {
+		err := s.bitsManager.UploadApp(app.GUID, appDeploy.Path)
+		if err != nil {
+			return AppDeployResponse{}, err
+		}
+	}
+
+	app, err = s.rollout(appDeploy)
+	if err != nil {
+		return AppDeployResponse{}, err
+	}
+	return AppDeployResponse{
+		App:             app,
+		RouteMapping:    rejoinMappingPort(app.Ports[0], mappings),
+		ServiceBindings: bindings,
+	}, nil
+}
+
+func (s Rolling) Restage(appDeploy AppDeploy) (AppDeployResponse, error) {
+	if appDeploy.App.State == constant.ApplicationStopped {
+		return s.standard.Restage(appDeploy)
+	}
+	app, err := s.rollout(appDeploy)
+	if err != nil {
+		return AppDeployResponse{}, err
+	}
+	return AppDeployResponse{
+		App:             app,
+		RouteMapping:    appDeploy.Mappings,
+		ServiceBindings: appDeploy.ServiceBindings,
+	}, nil
+}
+
+// rollout - stage latest package of app and deploy resulting droplet
+func (s Rolling) rollout(appDeploy AppDeploy) (ccv2.Application, error) {
+	pkg, err := s.latestPackage(appDeploy)
+	if err != nil {
+		return ccv2.Application{}, err
+	}
+	dropletGUID, err := s.stage(appDeploy, pkg)
+	if err != nil {
+		return ccv2.Application{}, s.runBinder.processDeployErr(err, appDeploy)
+	}
+	err = s.deploy(appDeploy, dropletGUID)
+	if err != nil {
+		return ccv2.Application{}, s.runBinder.processDeployErr(err, appDeploy)
+	}
+	app, _, err := s.client.GetApplication(appDeploy.App.GUID)
+	return app, err
+}
+
+func (s Rolling) latestPackage(appDeploy AppDeploy) (ccv3.Package, error) {
+	var pkg ccv3.Package
+	err := common.PollingWithTimeout(func() (bool, error) {
+		pkgs, _, err := s.clientV3.GetPackages(
+			ccv3.Query{Key: ccv3.AppGUIDFilter, Values: []string{appDeploy.App.GUID}},
+			ccv3.Query{Key: ccv3.OrderBy, Values: []string{"-created_at"}},
+			ccv3.Query{Key: ccv3.PerPage, Values: []string{"1"}},
+		)
+		if err != nil {
+			return true, err
+		}
+		if len(pkgs) == 0 {
+			return true, fmt.Errorf("No package found for app %s", appDeploy.App.Name)
+		}
+		pkg = pkgs[0]
+		switch pkg.State {
+		case constantV3.PackageReady:
+			return true, nil
+		case constantV3.PackageFailed, constantV3.PackageExpired:
+			return true, fmt.Errorf("Package %s of app %s is in state %s", pkg.GUID, appDeploy.App.Name, pkg.State)
+		}
+		return false, nil
+	}, 5*time.Second, appDeploy.StageTimeout)
+	return pkg, err
+}
+
+func (s Rolling) stage(appDeploy AppDeploy, pkg ccv3.Package) (string, error) {
+	build, _, err := s.clientV3.CreateBuild(ccv3.Build{PackageGUID: pkg.GUID})
+	if err != nil {
+		return "", err
+	}
+	err = common.PollingWithTimeout(func() (bool, error) {
+		build, _, err = s.clientV3.GetBuild(build.GUID)
+		if err != nil {
+			return true, err
+		}
+		switch build.State {
+		case constantV3.BuildStaged:
+			return true, nil
+		case constantV3.BuildFailed:
+			return true, fmt.Errorf("Staging failed for app %s, reason: %s", appDeploy.App.Name, build.Error)
+		}
+		return false, nil
+	}, 5*time.Second, appDeploy.StageTimeout)
+	if err != nil {
+		return "", err
+	}
+	return build.DropletGUID, nil
+}
+
+func (s Rolling) deploy(appDeploy AppDeploy, dropletGUID string) error {
+	deploymentGUID, _, err := s.clientV3.CreateApplicationDeployment(appDeploy.App.GUID, dropletGUID)
+	if err != nil {
+		return err
+	}
+	// each instance must start one after another, use start timeout for each of them
+	timeout := appDeploy.StartTimeout * time.Duration(appDeploy.App.Instances.Value+1)
+	return common.PollingWithTimeout(func() (bool, error) {
+		deployment, err := s.getDeployment(deploymentGUID)
+		if err != nil {
+			return true, err
+		}
+		return deploymentFinished(deployment, deploymentGUID, appDeploy.App.Name)
+	}, 5*time.Second, timeout)
+}
+
+func (s Rolling) getDeployment(deploymentGUID string) (rollingDeployment, error) {
+	var deployment rollingDeployment
+	req, err := s.rawClient.NewRequest("GET", fmt.Sprintf("/v3/deployments/%s", deploymentGUID), nil)
+	if err != nil {
+		return deployment, err
+	}
+	resp, err := s.rawClient.Do(req)
+	if err != nil {
+		return deployment, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		b, err := ioutil.ReadAll(resp.Body)
+		if err != nil {
+			return deployment, err
+		}
+		return deployment, ccerror.RawHTTPStatusError{
+			StatusCode:  resp.StatusCode,
+			RawResponse: b,
+		}
+	}
+	err = json.NewDecoder(resp.Body).Decode(&deployment)
+	return deployment, err
+}
+
+// deploymentFinished - true when deployment is over, deployment stopped before being deployed is an error
+func deploymentFinished(deployment rollingDeployment, deploymentGUID string, appName string) (bool, error) {
+	reason := deployment.Status.Reason
+	if reason == "" {
+		reason = deployment.State
+	}
+	switch reason {
+	case string(constantV3.DeploymentDeployed):
+		return true, nil
+	case "FAILED", "CANCELING", string(constantV3.DeploymentCanceled), "DEGENERATE", "SUPERSEDED":
+		return true, fmt.Errorf("Deployment %s of app %s has stopped with status %s", deploymentGUID, appName, reason)
+	}
+	if deployment.Status.Value == "FINALIZED" {
+		return true, fmt.Errorf("Deployment %s of app %s has been finalized with status %s", deploymentGUID, appName, reason)
+	}
+	return false, nil
+}
+
+func (Rolling) IsCreateNewApp() bool {
+	return false
+}
+
+func (Rolling) Names() []string {
+	return []string{"rolling"}
+}
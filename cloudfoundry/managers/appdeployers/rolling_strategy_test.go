@@ -0,0 +1,35 @@
+package appdeployers
+
+import (
+	"testing"
+)
+
+func TestDeploymentFinished(t *testing.T) {
+	for _, c := range []struct {
+		state    string
+		value    string
+		reason   string
+		finished bool
+		failed   bool
+	}{
+		{"DEPLOYING", "ACTIVE", "DEPLOYING", false, false},
+		{"DEPLOYED", "FINALIZED", "DEPLOYED", true, false},
+		{"CANCELED", "FINALIZED", "CANCELED", true, true},
+		{"", "ACTIVE", "CANCELING", true, true},
+		{"", "FINALIZED", "SUPERSEDED", true, true},
+		{"", "FAILED", "FAILED", true, true},
+		// older cloud controllers give state only
+		{"DEPLOYING", "", "", false, false},
+		{"DEPLOYED", "", "", true, false},
+		{"CANCELED", "", "", true, true},
+	} {
+		var deployment rollingDeployment
+		deployment.State = c.state
+		deployment.Status.Value = c.value
+		deployment.Status.Reason = c.reason
+		finished, err := deploymentFinished(deployment, "deployment-guid", "my-app")
+		if finished != c.finished || (err != nil) != c.failed {
+			t.Fatalf("unexpected result for deployment %+v: finished %t, error %v", deployment, finished, err)
+		}
+	}
+}
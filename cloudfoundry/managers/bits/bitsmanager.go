@@ -2,6 +2,7 @@ package bits
 
 import (
 	"bytes"
+	"code.cloudfoundry.org/cli/api/cloudcontroller/ccerror"
 	"code.cloudfoundry.org/cli/api/cloudcontroller/ccv2"
 	"code.cloudfoundry.org/cli/api/cloudcontroller/ccv3"
 	"encoding/json"
@@ -191,6 +192,49 @@ func (m BitsManager) UploadApp(appGUID string, path string) error {
 	return nil
 }
 
+// GetAppBitsChecksum - Retrieve checksum of latest ready bits package of an app,
+// checksum is empty when app has no bits package (e.g. docker app)
+func (m BitsManager) GetAppBitsChecksum(appGUID string) (string, error) {
+	path := fmt.Sprintf("/v3/apps/%s/packages?types=bits&states=READY&order_by=-created_at&per_page=1", appGUID)
+	req, err := m.rawClient.NewRequest("GET", path, nil)
+	if err != nil {
+		return "", err
+	}
+	resp, err := m.rawClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		b, err := ioutil.ReadAll(resp.Body)
+		if err != nil {
+			return "", err
+		}
+		return "", ccerror.RawHTTPStatusError{
+			StatusCode:  resp.StatusCode,
+			RawResponse: b,
+		}
+	}
+	var pkgs struct {
+		Resources []struct {
+			Data struct {
+				Checksum struct {
+					Type  string `json:"type"`
+					Value string `json:"value"`
+				} `json:"checksum"`
+			} `json:"data"`
+		} `json:"resources"`
+	}
+	err = json.NewDecoder(resp.Body).Decode(&pkgs)
+	if err != nil {
+		return "", err
+	}
+	if len(pkgs.Resources) == 0 {
+		return "", nil
+	}
+	return pkgs.Resources[0].Data.Checksum.Value, nil
+}
+
 func (m BitsManager) predictPartApp(filesize int64, boundary string) int64 {
 	buf := new(bytes.Buffer)
 	mpw := multipart.NewWriter(buf)
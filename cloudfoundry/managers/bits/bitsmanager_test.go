@@ -0,0 +1,40 @@
+package bits
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"code.cloudfoundry.org/cli/api/cloudcontroller/ccerror"
+	"github.com/terraform-providers/terraform-provider-cloudfoundry/cloudfoundry/managers/raw"
+)
+
+func TestGetAppBitsChecksum(t *testing.T) {
+	status := http.StatusOK
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		if status == http.StatusOK {
+			fmt.Fprint(w, `{"resources":[{"data":{"checksum":{"type":"sha256","value":"abc"}}}]}`)
+			return
+		}
+		fmt.Fprint(w, `{"errors":[{"code":10002,"title":"CF-NotAuthenticated","detail":"Authentication error"}]}`)
+	}))
+	defer server.Close()
+	m := NewBitsManager(nil, nil, raw.NewRawClient(raw.RawClientConfig{ApiEndpoint: server.URL}), http.DefaultClient)
+
+	checksum, err := m.GetAppBitsChecksum("app-guid")
+	if err != nil || checksum != "abc" {
+		t.Fatalf("expected checksum abc but found '%s' (error: %v)", checksum, err)
+	}
+
+	// a failed request must not look like an app without bits package
+	for _, status = range []int{http.StatusUnauthorized, http.StatusNotFound, http.StatusBadGateway} {
+		checksum, err = m.GetAppBitsChecksum("app-guid")
+		httpErr, ok := err.(ccerror.RawHTTPStatusError)
+		if !ok || httpErr.StatusCode != status || checksum != "" {
+			t.Fatalf("expected error with status %d but found '%s' (error: %v)", status, checksum, err)
+		}
+	}
+}
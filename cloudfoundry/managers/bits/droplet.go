@@ -0,0 +1,94 @@
+package bits
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+
+	"code.cloudfoundry.org/cli/api/cloudcontroller/ccerror"
+)
+
+// Droplet - droplet of an app as given by cloud controller v3 api
+type Droplet struct {
+	GUID      string `json:"guid"`
+	State     string `json:"state"`
+	Stack     string `json:"stack"`
+	CreatedAt string `json:"created_at"`
+	Checksum  struct {
+		Type  string `json:"type"`
+		Value string `json:"value"`
+	} `json:"checksum"`
+	Buildpacks []struct {
+		Name          string `json:"name"`
+		BuildpackName string `json:"buildpack_name"`
+		Version       string `json:"version"`
+	} `json:"buildpacks"`
+}
+
+// GetAppDropletCurrent - Retrieve current droplet of an app
+func (m BitsManager) GetAppDropletCurrent(appGUID string) (Droplet, error) {
+	req, err := m.rawClient.NewRequest("GET", fmt.Sprintf("/v3/apps/%s/droplets/current", appGUID), nil)
+	if err != nil {
+		return Droplet{}, err
+	}
+	resp, err := m.rawClient.Do(req)
+	if err != nil {
+		return Droplet{}, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != 200 {
+		b, err := ioutil.ReadAll(resp.Body)
+		if err != nil {
+			return Droplet{}, err
+		}
+		return Droplet{}, ccerror.RawHTTPStatusError{
+			StatusCode:  resp.StatusCode,
+			RawResponse: b,
+		}
+	}
+	var droplet Droplet
+	err = json.NewDecoder(resp.Body).Decode(&droplet)
+	if err != nil {
+		return Droplet{}, err
+	}
+	return droplet, nil
+}
+
+// DownloadDroplet - Download droplet in full stream into w and give back its sha256 checksum
+// Checksum given by cloud controller is verified when it is a sha256 checksum
+func (m BitsManager) DownloadDroplet(droplet Droplet, w io.Writer) (string, error) {
+	req, err := m.rawClient.NewRequest("GET", fmt.Sprintf("/v3/droplets/%s/download", droplet.GUID), nil)
+	if err != nil {
+		return "", err
+	}
+	// cloud controller redirects to blobstore, http client follows redirection
+	// and drops authorization header when blobstore is on another host
+	resp, err := m.rawClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != 200 {
+		b, err := ioutil.ReadAll(resp.Body)
+		if err != nil {
+			return "", err
+		}
+		return "", ccerror.RawHTTPStatusError{
+			StatusCode:  resp.StatusCode,
+			RawResponse: b,
+		}
+	}
+	h := sha256.New()
+	_, err = io.Copy(io.MultiWriter(w, h), resp.Body)
+	if err != nil {
+		return "", err
+	}
+	checksum := hex.EncodeToString(h.Sum(nil))
+	if droplet.Checksum.Type == "sha256" && droplet.Checksum.Value != checksum {
+		return "", fmt.Errorf("Downloaded droplet %s has checksum %s but %s was expected", droplet.GUID, checksum, droplet.Checksum.Value)
+	}
+	return checksum, nil
+}
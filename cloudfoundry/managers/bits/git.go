@@ -0,0 +1,239 @@
+package bits
+
+import (
+	"archive/zip"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	"github.com/go-git/go-git/v5/plumbing/transport/client"
+	gitssh "github.com/go-git/go-git/v5/plumbing/transport/ssh"
+	"github.com/go-git/go-git/v5/storage/memory"
+)
+
+// zipEpoch is the modification time set on every zip entry, this makes
+// two zips of the same commit byte for byte identical
+var zipEpoch = time.Date(1980, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+// GitSource - describe bits which must be retrieved from a git repository
+type GitSource struct {
+	// URL of the repository, can be any url supported by git (https, ssh, file or scp-like)
+	URL string
+	// Ref is a branch, a tag or a commit sha, remote HEAD is used when empty
+	Ref string
+	// Subdirectory inside repository to use as root of the zip
+	Subdirectory string
+	// SSHKeyFile is path to a private key used when cloning over ssh
+	SSHKeyFile string
+}
+
+func (s GitSource) auth() (transport.AuthMethod, error) {
+	if s.SSHKeyFile == "" {
+		return nil, nil
+	}
+	user := "git"
+	ep, err := transport.NewEndpoint(s.URL)
+	if err == nil && ep.User != "" {
+		user = ep.User
+	}
+	return gitssh.NewPublicKeysFromFile(user, s.SSHKeyFile, "")
+}
+
+// ResolveGitRef - Resolve ref of a git source to a commit sha without cloning repository (like git ls-remote)
+// It returns an empty sha when ref can only be resolved by cloning (e.g. ref is an abbreviated commit sha)
+func ResolveGitRef(src GitSource) (string, error) {
+	if plumbing.IsHash(src.Ref) {
+		return src.Ref, nil
+	}
+	auth, err := src.auth()
+	if err != nil {
+		return "", err
+	}
+	ep, err := transport.NewEndpoint(src.URL)
+	if err != nil {
+		return "", err
+	}
+	c, err := client.NewClient(ep)
+	if err != nil {
+		return "", err
+	}
+	s, err := c.NewUploadPackSession(ep, auth)
+	if err != nil {
+		return "", err
+	}
+	defer s.Close()
+	ar, err := s.AdvertisedReferences()
+	if err != nil {
+		return "", err
+	}
+	if src.Ref == "" {
+		if ar.Head == nil {
+			return "", nil
+		}
+		return ar.Head.String(), nil
+	}
+	candidates := []plumbing.ReferenceName{
+		plumbing.ReferenceName(src.Ref),
+		plumbing.NewBranchReferenceName(src.Ref),
+		plumbing.NewTagReferenceName(src.Ref),
+	}
+	for _, candidate := range candidates {
+		// annotated tags must be peeled to the commit they point to
+		if hash, ok := ar.Peeled[candidate.String()]; ok {
+			return hash.String(), nil
+		}
+		hash, ok := ar.References[candidate.String()]
+		if !ok {
+			continue
+		}
+		if candidate.IsTag() {
+			// server did not advertise peeled tags, hash may be a tag object and not a commit
+			return "", nil
+		}
+		return hash.String(), nil
+	}
+	return "", nil
+}
+
+// ZipGit - Clone a git source in memory and write a deterministic zip of the resolved tree into w
+// files are sorted and get a fixed modification time, the same commit will always give the same zip
+// It returns the sha of the commit which has been zipped
+func ZipGit(src GitSource, w io.Writer) (string, error) {
+	auth, err := src.auth()
+	if err != nil {
+		return "", err
+	}
+	repo, err := git.Clone(memory.NewStorage(), nil, &git.CloneOptions{
+		URL:  src.URL,
+		Auth: auth,
+	})
+	if err != nil {
+		return "", fmt.Errorf("Error when cloning git repository %s: %s", src.URL, err.Error())
+	}
+
+	hash, err := resolveRevision(repo, src.Ref)
+	if err != nil {
+		return "", err
+	}
+	commit, err := repo.CommitObject(*hash)
+	if err != nil {
+		return "", err
+	}
+	tree, err := commit.Tree()
+	if err != nil {
+		return "", err
+	}
+	subdir := strings.Trim(path.Clean("/"+src.Subdirectory), "/")
+	if subdir != "" {
+		tree, err = tree.Tree(subdir)
+		if err != nil {
+			return "", fmt.Errorf("Subdirectory %s not found at commit %s: %s", subdir, commit.Hash, err.Error())
+		}
+	}
+
+	files := make([]*object.File, 0)
+	err = tree.Files().ForEach(func(f *object.File) error {
+		files = append(files, f)
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].Name < files[j].Name
+	})
+
+	zipWriter := zip.NewWriter(w)
+	for _, f := range files {
+		err := writeZipEntry(zipWriter, f)
+		if err != nil {
+			zipWriter.Close()
+			return "", err
+		}
+	}
+	err = zipWriter.Close()
+	if err != nil {
+		return "", err
+	}
+	return commit.Hash.String(), nil
+}
+
+// ZipGitToFile - Same as ZipGit but write zip to a file named after repository inside a temporary directory
+// caller is responsible of removing the directory
+func ZipGitToFile(src GitSource) (string, string, error) {
+	dir, err := ioutil.TempDir("", "cf-git-bits")
+	if err != nil {
+		return "", "", err
+	}
+	name := strings.TrimSuffix(path.Base(strings.TrimSuffix(filepath.ToSlash(src.URL), "/")), ".git")
+	f, err := os.Create(filepath.Join(dir, name+".zip"))
+	if err != nil {
+		os.RemoveAll(dir)
+		return "", "", err
+	}
+	defer f.Close()
+	sha, err := ZipGit(src, f)
+	if err != nil {
+		os.RemoveAll(dir)
+		return "", "", err
+	}
+	return f.Name(), sha, nil
+}
+
+func resolveRevision(repo *git.Repository, ref string) (*plumbing.Hash, error) {
+	if ref == "" {
+		head, err := repo.Head()
+		if err != nil {
+			return nil, err
+		}
+		hash := head.Hash()
+		return &hash, nil
+	}
+	// branches are only known as remote branches in a bare clone
+	for _, rev := range []string{ref, git.DefaultRemoteName + "/" + ref} {
+		hash, err := repo.ResolveRevision(plumbing.Revision(rev))
+		if err == nil {
+			return hash, nil
+		}
+	}
+	return nil, fmt.Errorf("Reference %s can't be resolved in git repository", ref)
+}
+
+func writeZipEntry(zipWriter *zip.Writer, f *object.File) error {
+	header := &zip.FileHeader{
+		Name:     f.Name,
+		Method:   zip.Deflate,
+		Modified: zipEpoch,
+	}
+	switch f.Mode {
+	case filemode.Executable:
+		header.SetMode(0755)
+	case filemode.Symlink:
+		header.SetMode(0777 | os.ModeSymlink)
+		header.Method = zip.Store
+	default:
+		header.SetMode(0644)
+	}
+	entry, err := zipWriter.CreateHeader(header)
+	if err != nil {
+		return err
+	}
+	r, err := f.Reader()
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+	_, err = io.Copy(entry, r)
+	return err
+}
@@ -0,0 +1,242 @@
+package bits
+
+import (
+	"archive/zip"
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+type testGitRepo struct {
+	bareDir  string
+	first    string
+	second   string
+	tagged   string
+	branched string
+}
+
+func commitFiles(t *testing.T, repo *git.Repository, workDir string, files map[string]string) string {
+	wt, err := repo.Worktree()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, content := range files {
+		p := filepath.Join(workDir, name)
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatal(err)
+		}
+		mode := os.FileMode(0644)
+		if filepath.Base(filepath.Dir(p)) == "bin" {
+			mode = 0755
+		}
+		if err := ioutil.WriteFile(p, []byte(content), mode); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := wt.Add(name); err != nil {
+			t.Fatal(err)
+		}
+	}
+	hash, err := wt.Commit("commit", &git.CommitOptions{
+		Author: &object.Signature{Name: "test", Email: "test@example.com", When: time.Now()},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return hash.String()
+}
+
+// newTestGitRepo - create a bare repository with two commits on master,
+// an annotated tag on first commit and a branch with a third commit
+func newTestGitRepo(t *testing.T) (testGitRepo, func()) {
+	tmpDir, err := ioutil.TempDir("", "provider-cf-git")
+	if err != nil {
+		t.Fatal(err)
+	}
+	workDir := filepath.Join(tmpDir, "work")
+	repo, err := git.PlainInit(workDir, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := testGitRepo{bareDir: filepath.Join(tmpDir, "repo.git")}
+	r.first = commitFiles(t, repo, workDir, map[string]string{
+		"README.md":          "readme",
+		"app/Procfile":       "web: ./app",
+		"app/bin/run":        "#!/bin/sh",
+		"buildpack/manifest": "v1",
+	})
+	_, err = repo.CreateTag("v1.0.0", plumbing.NewHash(r.first), &git.CreateTagOptions{
+		Tagger:  &object.Signature{Name: "test", Email: "test@example.com", When: time.Now()},
+		Message: "v1.0.0",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.tagged = r.first
+	r.second = commitFiles(t, repo, workDir, map[string]string{
+		"buildpack/manifest": "v2",
+	})
+
+	wt, _ := repo.Worktree()
+	err = wt.Checkout(&git.CheckoutOptions{
+		Branch: plumbing.NewBranchReferenceName("feature"),
+		Create: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.branched = commitFiles(t, repo, workDir, map[string]string{
+		"app/feature": "feature",
+	})
+	err = wt.Checkout(&git.CheckoutOptions{
+		Branch: plumbing.NewBranchReferenceName("master"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = git.PlainInit(r.bareDir, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = repo.CreateRemote(&config.RemoteConfig{Name: "bare", URLs: []string{r.bareDir}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = repo.Push(&git.PushOptions{
+		RemoteName: "bare",
+		RefSpecs:   []config.RefSpec{"refs/heads/*:refs/heads/*", "refs/tags/*:refs/tags/*"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return r, func() { os.RemoveAll(tmpDir) }
+}
+
+func zipEntries(t *testing.T, b []byte) map[string]*zip.File {
+	zr, err := zip.NewReader(bytes.NewReader(b), int64(len(b)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	entries := make(map[string]*zip.File)
+	for _, f := range zr.File {
+		entries[f.Name] = f
+	}
+	return entries
+}
+
+func TestZipGit(t *testing.T) {
+	r, clean := newTestGitRepo(t)
+	defer clean()
+
+	cases := map[string]struct {
+		Src         GitSource
+		Commit      string
+		Files       []string
+		Executables []string
+	}{
+		"head": {
+			Src:    GitSource{URL: r.bareDir},
+			Commit: r.second,
+			Files:  []string{"README.md", "app/Procfile", "app/bin/run", "buildpack/manifest"},
+		},
+		"tag": {
+			Src:    GitSource{URL: r.bareDir, Ref: "v1.0.0"},
+			Commit: r.tagged,
+			Files:  []string{"README.md", "app/Procfile", "app/bin/run", "buildpack/manifest"},
+		},
+		"branch": {
+			Src:    GitSource{URL: r.bareDir, Ref: "feature"},
+			Commit: r.branched,
+			Files:  []string{"README.md", "app/Procfile", "app/bin/run", "app/feature", "buildpack/manifest"},
+		},
+		"commit": {
+			Src:    GitSource{URL: r.bareDir, Ref: r.first},
+			Commit: r.first,
+			Files:  []string{"README.md", "app/Procfile", "app/bin/run", "buildpack/manifest"},
+		},
+		"subdirectory": {
+			Src:         GitSource{URL: r.bareDir, Ref: "feature", Subdirectory: "app/"},
+			Commit:      r.branched,
+			Files:       []string{"Procfile", "bin/run", "feature"},
+			Executables: []string{"bin/run"},
+		},
+	}
+
+	for tn, tc := range cases {
+		buf := new(bytes.Buffer)
+		commit, err := ZipGit(tc.Src, buf)
+		if err != nil {
+			t.Fatalf("bad: %s, err: %#v", tn, err)
+		}
+		if commit != tc.Commit {
+			t.Fatalf("bad: %s\n\n expected commit: %s\n got: %s", tn, tc.Commit, commit)
+		}
+		entries := zipEntries(t, buf.Bytes())
+		if len(entries) != len(tc.Files) {
+			t.Fatalf("bad: %s\n\n expected files: %v\n got: %v", tn, tc.Files, entries)
+		}
+		for _, name := range tc.Files {
+			f, ok := entries[name]
+			if !ok {
+				t.Fatalf("bad: %s\n\n file %s not found in zip", tn, name)
+			}
+			if !f.Modified.UTC().Equal(zipEpoch) {
+				t.Fatalf("bad: %s\n\n file %s has modification time %s", tn, name, f.Modified)
+			}
+		}
+		for _, name := range tc.Executables {
+			if entries[name].Mode().Perm()&0111 == 0 {
+				t.Fatalf("bad: %s\n\n file %s is not executable", tn, name)
+			}
+		}
+
+		resolved, err := ResolveGitRef(tc.Src)
+		if err != nil {
+			t.Fatalf("bad: %s, err: %#v", tn, err)
+		}
+		if resolved != tc.Commit {
+			t.Fatalf("bad: %s\n\n expected resolved ref: %s\n got: %s", tn, tc.Commit, resolved)
+		}
+	}
+}
+
+func TestZipGitDeterministic(t *testing.T) {
+	r, clean := newTestGitRepo(t)
+	defer clean()
+
+	first := new(bytes.Buffer)
+	_, err := ZipGit(GitSource{URL: r.bareDir}, first)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second := new(bytes.Buffer)
+	_, err = ZipGit(GitSource{URL: r.bareDir}, second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(first.Bytes(), second.Bytes()) {
+		t.Fatal("zipping twice the same commit must give the same zip")
+	}
+}
+
+func TestZipGitErrors(t *testing.T) {
+	r, clean := newTestGitRepo(t)
+	defer clean()
+
+	_, err := ZipGit(GitSource{URL: r.bareDir, Ref: "unknown"}, ioutil.Discard)
+	if err == nil {
+		t.Fatal("expected error on unknown ref")
+	}
+	_, err = ZipGit(GitSource{URL: r.bareDir, Subdirectory: "unknown"}, ioutil.Discard)
+	if err == nil {
+		t.Fatal("expected error on unknown subdirectory")
+	}
+}
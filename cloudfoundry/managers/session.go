@@ -39,6 +39,9 @@ type Session struct {
 	// Used for direct endpoint calls
 	RawClient *raw.RawClient
 
+	// Used for direct endpoint calls on uaa with ClientUAA credentials
+	RawClientUAA *raw.RawClient
+
 	// http client used for normal request
 	HttpClient *http.Client
 
@@ -300,6 +303,21 @@ func (s *Session) init(config *configv3.Config, configUaa *configv3.Config, conf
 		configUaa.SetRefreshToken(refreshTokenSess)
 		s.ClientUAA = uaaClientSess
 		uaaAuthWrapperSess.SetClient(uaaClientSess)
+
+		// raw client on uaa for endpoints not available in ClientUAA
+		authWrapperRawUAA := ccWrapper.NewUAAAuthentication(uaaClientSess, configUaa)
+		rawUAAWrappers := []ccv3.ConnectionWrapper{
+			authWrapperRawUAA,
+			NewRetryRequest(config.RequestRetryCount()),
+		}
+		if IsDebugMode() {
+			rawUAAWrappers = append(rawUAAWrappers, ccWrapper.NewRequestLogger(NewRequestLogger()))
+		}
+		s.RawClientUAA = raw.NewRawClient(raw.RawClientConfig{
+			ApiEndpoint:       uaaClientSess.UAALink(),
+			SkipSSLValidation: config.SkipSSLValidation(),
+			DialTimeout:       config.DialTimeout(),
+		}, rawUAAWrappers...)
 	}
 	// -------------------------
 
@@ -391,7 +409,8 @@ func (s *Session) loadDeployer() {
 	s.RunBinder = appdeployers.NewRunBinder(s.ClientV2, s.NOAAClient)
 	stdStrategy := appdeployers.NewStandard(s.BitsManager, s.ClientV2, s.RunBinder)
 	bgStrategy := appdeployers.NewBlueGreenV2(s.BitsManager, s.ClientV2, s.RunBinder, stdStrategy)
-	s.Deployer = appdeployers.NewDeployer(stdStrategy, bgStrategy)
+	rollingStrategy := appdeployers.NewRolling(s.BitsManager, s.ClientV2, s.ClientV3, s.RawClient, s.RunBinder, stdStrategy)
+	s.Deployer = appdeployers.NewDeployer(stdStrategy, bgStrategy, rollingStrategy)
 }
 
 func (s *Session) loadDefaultQuotaGuid(quotaName string) error {
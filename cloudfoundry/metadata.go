@@ -145,6 +145,10 @@ func metadataRead(t metadataType, d *schema.ResourceData, meta interface{}, forc
 	annotations := make(map[string]interface{})
 	if IsImportState(d) {
 		for k, v := range oldMetadata.Annotations {
+			// set by provider itself on apps, not by user
+			if t == appMetadata && k == appStrategyAnnotation {
+				continue
+			}
 			annotations[k] = v
 		}
 	} else {
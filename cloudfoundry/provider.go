@@ -112,12 +112,16 @@ func Provider() *schema.Provider {
 			"cloudfoundry_user_provided_service": dataSourceUserProvidedService(),
 			"cloudfoundry_service_key":           dataSourceServiceKey(),
 			"cloudfoundry_service":               dataSourceService(),
+			"cloudfoundry_service_offerings":     dataSourceServiceOfferings(),
+			"cloudfoundry_service_plans":         dataSourceServicePlans(),
 			"cloudfoundry_app":                   dataSourceApp(),
+			"cloudfoundry_app_droplet":           dataSourceAppDroplet(),
 		},
 
 		ResourcesMap: map[string]*schema.Resource{
 			"cloudfoundry_feature_flags":                 resourceConfig(),
 			"cloudfoundry_user":                          resourceUser(),
+			"cloudfoundry_users":                         resourceUsers(),
 			"cloudfoundry_domain":                        resourceDomain(),
 			"cloudfoundry_private_domain_access":         resourcePrivateDomainAccess(),
 			"cloudfoundry_asg":                           resourceAsg(),
@@ -129,12 +133,25 @@ func Provider() *schema.Provider {
 			"cloudfoundry_space":                         resourceSpace(),
 			"cloudfoundry_space_users":                   resourceSpaceUsers(),
 			"cloudfoundry_org_users":                     resourceOrgUsers(),
+			"cloudfoundry_role":                          resourceRole(),
+			"cloudfoundry_uaa_group":                     resourceUAAGroup(),
+			"cloudfoundry_uaa_group_membership":          resourceUAAGroupMembership(),
+			"cloudfoundry_uaa_client":                    resourceUAAClient(),
+			"cloudfoundry_uaa_identity_zone":             resourceUAAIdentityZone(),
+			"cloudfoundry_uaa_ldap_provider":             resourceUAALDAPProvider(),
+			"cloudfoundry_uaa_saml_provider":             resourceUAASAMLProvider(),
+			"cloudfoundry_uaa_oidc_provider":             resourceUAAOIDCProvider(),
 			"cloudfoundry_service_broker":                resourceServiceBroker(),
 			"cloudfoundry_service_plan_access":           resourceServicePlanAccess(),
+			"cloudfoundry_service_plan_visibility":       resourceServicePlanVisibility(),
 			"cloudfoundry_service_instance":              resourceServiceInstance(),
+			"cloudfoundry_service_instance_share":        resourceServiceInstanceShare(),
 			"cloudfoundry_service_key":                   resourceServiceKey(),
+			"cloudfoundry_service_key_rotation":          resourceServiceKeyRotation(),
+			"cloudfoundry_service_binding":               resourceServiceBinding(),
 			"cloudfoundry_user_provided_service":         resourceUserProvidedService(),
 			"cloudfoundry_buildpack":                     resourceBuildpack(),
+			"cloudfoundry_buildpack_order":               resourceBuildpackOrder(),
 			"cloudfoundry_route":                         resourceRoute(),
 			"cloudfoundry_route_service_binding":         resourceRouteServiceBinding(),
 			"cloudfoundry_app":                           resourceApp(),
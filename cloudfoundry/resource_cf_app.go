@@ -28,6 +28,11 @@ const (
 	DefaultAppPort      = 8080
 )
 
+const bitsChecksumKey = "bits_checksum"
+
+// appStrategyAnnotation - annotation where deploy strategy used by terraform is recorded on app
+const appStrategyAnnotation = "terraform.cloudfoundry.org/strategy"
+
 func resourceApp() *schema.Resource {
 	return &schema.Resource{
 
@@ -77,7 +82,6 @@ func resourceApp() *schema.Resource {
 			"stack": &schema.Schema{
 				Type:     schema.TypeString,
 				Optional: true,
-				ForceNew: true,
 				Computed: true,
 			},
 			"buildpack": &schema.Schema{
@@ -110,14 +114,21 @@ func resourceApp() *schema.Resource {
 				Type:         schema.TypeString,
 				Optional:     true,
 				Default:      "none",
-				Description:  "Deployment strategy, default to none but accept blue-green strategy",
+				Description:  "Deployment strategy, default to none but accept blue-green and rolling strategies",
 				ValidateFunc: validateStrategy,
 			},
 			"path": &schema.Schema{
 				Type:          schema.TypeString,
 				Optional:      true,
 				Description:   "Path to an app zip in the form of unix path or http url",
-				ConflictsWith: []string{"docker_image", "docker_credentials"},
+				ConflictsWith: []string{"docker_image", "docker_credentials", gitKey},
+			},
+			gitKey:       gitSchema("path", "docker_image", "docker_credentials"),
+			gitCommitKey: gitCommitSchema(),
+			bitsChecksumKey: &schema.Schema{
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "Checksum of app bits package at last deployment, app is redeployed when bits are changed outside of terraform",
 			},
 			"source_code_hash": &schema.Schema{
 				Type:     schema.TypeString,
@@ -126,13 +137,13 @@ func resourceApp() *schema.Resource {
 			"docker_image": &schema.Schema{
 				Type:          schema.TypeString,
 				Optional:      true,
-				ConflictsWith: []string{"path"},
+				ConflictsWith: []string{"path", gitKey},
 			},
 			"docker_credentials": &schema.Schema{
 				Type:          schema.TypeMap,
 				Optional:      true,
 				Sensitive:     true,
-				ConflictsWith: []string{"path"},
+				ConflictsWith: []string{"path", gitKey},
 			},
 			"service_binding": &schema.Schema{
 				Type:     schema.TypeList,
@@ -230,21 +241,45 @@ func resourceApp() *schema.Resource {
 		},
 
 		CustomizeDiff: func(ctx context.Context, diff *schema.ResourceDiff, meta interface{}) error {
-			if diff.HasChange("docker_image") || diff.HasChange("path") {
+			if diff.HasChange("docker_image") || diff.HasChange("path") || diff.HasChange(gitKey) {
 				oldImg, newImg := diff.GetChange("docker_image")
 				oldPath, newPath := diff.GetChange("path")
+				oldGit, newGit := diff.GetChange(gitKey)
 				if oldImg == "" && newImg != "" && newPath == "" {
 					return diff.ForceNew("docker_image")
 				}
-				if oldPath == "" && newPath != "" && newImg == "" {
+				if oldPath == "" && newPath != "" && newImg == "" && len(oldGit.([]interface{})) == 0 {
 					return diff.ForceNew("path")
 				}
+				if oldImg != "" && len(newGit.([]interface{})) > 0 {
+					return diff.ForceNew(gitKey)
+				}
+			}
+			session := meta.(*managers.Session)
+			if diff.HasChange("service_binding") {
+				for i := range diff.Get("service_binding").([]interface{}) {
+					err := bindingParamsCustomizeDiff(diff, session, fmt.Sprintf("service_binding.%d.service_instance", i),
+						fmt.Sprintf("service_binding.%d.params", i), fmt.Sprintf("service_binding.%d.params_json", i))
+					if err != nil {
+						return err
+					}
+				}
 			}
 			if diff.Id() == "" {
 				return nil
 			}
-			session := meta.(*managers.Session)
+			err := gitCommitCustomizeDiff(diff)
+			if err != nil {
+				return err
+			}
+			err = bitsChecksumCustomizeDiff(diff, session)
+			if err != nil {
+				return err
+			}
 			deployer := session.Deployer.Strategy(diff.Get("strategy").(string))
+			if diff.HasChange("stack") {
+				deployer = appInPlaceDeployer(session, diff.Get("strategy").(string))
+			}
 			if IsAppRestageNeeded(diff) ||
 				(deployer.IsCreateNewApp() && IsAppRestartNeeded(diff)) ||
 				(deployer.IsCreateNewApp() && IsAppCodeChange(diff)) {
@@ -285,16 +320,31 @@ func resourceAppCreate(ctx context.Context, d *schema.ResourceData, meta interfa
 	if err != nil {
 		return diag.FromErr(err)
 	}
+	path, commit, cleanup, err := gitBitsPrepare(d)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	defer cleanup()
+	appDeploy.Path = path
 
 	appResp, err := deployer.Deploy(appDeploy)
 	if err != nil {
 		return diag.FromErr(err)
 	}
 	AppDeployToResourceData(d, appResp)
+	d.Set(gitCommitKey, commit)
+	err = bitsChecksumRecord(d, session)
+	if err != nil {
+		return diag.FromErr(err)
+	}
 	err = metadataCreate(appMetadata, d, meta)
 	if err != nil {
 		return diag.FromErr(err)
 	}
+	err = appStrategyRecord(d, meta)
+	if err != nil {
+		return diag.FromErr(err)
+	}
 	return nil
 }
 
@@ -326,6 +376,18 @@ func resourceAppRead(ctx context.Context, d *schema.ResourceData, meta interface
 		RouteMapping:    mappings,
 		ServiceBindings: bindings,
 	})
+	checksum, err := session.BitsManager.GetAppBitsChecksum(d.Id())
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	recorded := d.Get(bitsChecksumKey).(string)
+	if recorded == "" {
+		// imported app or app deployed before checksum was recorded
+		d.Set(bitsChecksumKey, checksum)
+	} else if checksum != "" && checksum != recorded {
+		// recorded checksum is kept to let plan redeploy bits from terraform
+		log.Printf("[WARN] Bits of app %s have been changed outside of terraform (checksum %s, expected %s)", d.Id(), checksum, recorded)
+	}
 	err = metadataRead(appMetadata, d, meta, false)
 	if err != nil {
 		return diag.FromErr(err)
@@ -362,13 +424,23 @@ func reorderBindings(bindings []ccv2.ServiceBinding, currentBindings []interface
 	return finalBindings
 }
 
-func resourceAppUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+func resourceAppUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) (diags diag.Diagnostics) {
 	d.Partial(true)
 	session := meta.(*managers.Session)
 	defer func() {
 		d.Set("id_bg", d.Id())
 	}()
+	defer func() {
+		// blue-green creates a new app which must receive strategy too
+		if !diags.HasError() {
+			diags = append(diags, diag.FromErr(appStrategyRecord(d, meta))...)
+		}
+	}()
 	deployer := session.Deployer.Strategy(d.Get("strategy").(string))
+	// stack is migrated in place, a new app would lose guid, bindings and network policies of current one
+	if d.HasChange("stack") {
+		deployer = appInPlaceDeployer(session, d.Get("strategy").(string))
+	}
 
 	// sanitize any empty port under 1024
 	// this means that we are using not predefined port by user
@@ -446,12 +518,23 @@ func resourceAppUpdate(ctx context.Context, d *schema.ResourceData, meta interfa
 	// we are on the case where app code change so we can run directly deploy
 	// which will do all mapping and binding and update the app
 	if IsAppCodeChange(d) {
+		path, commit, cleanup, err := gitBitsPrepare(d)
+		if err != nil {
+			return diag.FromErr(err)
+		}
+		defer cleanup()
+		appDeploy.Path = path
 		appResp, err := deployer.Deploy(appDeploy)
 		if err != nil {
 			return diag.FromErr(err)
 		}
 		d.Partial(false)
 		AppDeployToResourceData(d, appResp)
+		d.Set(gitCommitKey, commit)
+		err = bitsChecksumRecord(d, session)
+		if err != nil {
+			return diag.FromErr(err)
+		}
 		return nil
 	}
 
@@ -558,6 +641,11 @@ func resourceAppUpdate(ctx context.Context, d *schema.ResourceData, meta interfa
 		}
 		d.Partial(false)
 		AppDeployToResourceData(d, appResp)
+		// blue-green restage copies bits to a new app
+		err = bitsChecksumRecord(d, session)
+		if err != nil {
+			return diag.FromErr(err)
+		}
 		return nil
 	}
 
@@ -578,7 +666,97 @@ func resourceAppUpdate(ctx context.Context, d *schema.ResourceData, meta interfa
 }
 
 func IsAppCodeChange(d ResourceChanger) bool {
-	return d.HasChange("path") || d.HasChange("source_code_hash")
+	return d.HasChange("path") || d.HasChange("source_code_hash") ||
+		d.HasChange(gitKey) || d.HasChange(gitCommitKey) || d.HasChange(bitsChecksumKey)
+}
+
+// appInPlaceDeployer - deployer of strategy keeping app, strategy creating a new app is replaced by rolling one
+func appInPlaceDeployer(session *managers.Session, strategy string) appdeployers.Strategy {
+	deployer := session.Deployer.Strategy(strategy)
+	if deployer.IsCreateNewApp() {
+		deployer = session.Deployer.Strategy("rolling")
+	}
+	return deployer
+}
+
+// appStrategyRecord - record deploy strategy of app in an annotation to let other resources restage app the same way,
+// no annotation means default strategy
+func appStrategyRecord(d *schema.ResourceData, meta interface{}) error {
+	if !isMetadataAPICompat(appMetadata, meta) {
+		return nil
+	}
+	strategy := d.Get("strategy").(string)
+	var value *string
+	if !isDefaultStrategy(strategy) {
+		value = &strategy
+	} else if old, _ := d.GetChange("strategy"); d.IsNewResource() || isDefaultStrategy(old.(string)) {
+		return nil
+	}
+	payload := MetadataRequest{Metadata: Metadata{
+		Annotations: map[string]*string{appStrategyAnnotation: value},
+	}}
+	_, err := v3Do(meta.(*managers.Session), "PATCH", pathMetadata(appMetadata, d), payload, nil)
+	return err
+}
+
+// appRecordedStrategy - deploy strategy recorded on app, default strategy when app was not deployed by terraform
+func appRecordedStrategy(session *managers.Session, appGUID string) (string, error) {
+	var app struct {
+		Metadata Metadata `json:"metadata"`
+	}
+	_, err := v3Do(session, "GET", fmt.Sprintf("/v3/apps/%s", appGUID), nil, &app)
+	if err != nil {
+		return "", err
+	}
+	if strategy, ok := app.Metadata.Annotations[appStrategyAnnotation]; ok && strategy != nil {
+		return *strategy, nil
+	}
+	return appdeployers.DefaultStrategie, nil
+}
+
+// isDefaultStrategy - none is not a strategy name but falls back to default one
+func isDefaultStrategy(strategy string) bool {
+	strategy = strings.ToLower(strategy)
+	if strategy == "" || strategy == "none" {
+		return true
+	}
+	for _, name := range (appdeployers.Standard{}).Names() {
+		if name == strategy {
+			return true
+		}
+	}
+	return false
+}
+
+// bitsChecksumRecord - record checksum of bits deployed by terraform
+func bitsChecksumRecord(d *schema.ResourceData, session *managers.Session) error {
+	checksum, err := session.BitsManager.GetAppBitsChecksum(d.Id())
+	if err != nil {
+		return err
+	}
+	d.Set(bitsChecksumKey, checksum)
+	return nil
+}
+
+// bitsChecksumCustomizeDiff - Compare checksum of current bits package of app with the one recorded
+// at last deployment and mark it as changed when bits have been pushed outside of terraform
+func bitsChecksumCustomizeDiff(diff *schema.ResourceDiff, session *managers.Session) error {
+	if IsAppCodeChange(diff) {
+		return diff.SetNewComputed(bitsChecksumKey)
+	}
+	recorded := diff.Get(bitsChecksumKey).(string)
+	if recorded == "" {
+		return nil
+	}
+	checksum, err := session.BitsManager.GetAppBitsChecksum(diff.Id())
+	if err != nil {
+		log.Printf("[WARN] Could not retrieve bits checksum of app %s, skipping drift detection: %s", diff.Id(), err.Error())
+		return nil
+	}
+	if checksum == "" || checksum == recorded {
+		return nil
+	}
+	return diff.SetNewComputed(bitsChecksumKey)
 }
 
 func IsAppUpdateOnly(d ResourceChanger) bool {
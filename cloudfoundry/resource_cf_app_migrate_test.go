@@ -10,6 +10,7 @@ import (
 )
 
 func TestAppMigrateStateV0toV3(t *testing.T) {
+	testAccSkipWithoutEnvironment(t)
 	folderBits, _ = ioutil.TempDir("", "provider-cf-migrate-app")
 	defer os.RemoveAll(folderBits)
 	cases := map[string]struct {
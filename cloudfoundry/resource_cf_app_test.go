@@ -2,6 +2,7 @@ package cloudfoundry
 
 import (
 	"fmt"
+	"path/filepath"
 	"testing"
 
 	"code.cloudfoundry.org/cli/api/cloudcontroller/ccv2"
@@ -736,6 +737,281 @@ func TestAccResApp_app_bluegreen(t *testing.T) {
 		})
 }
 
+const appResourceStack = `
+
+data "cloudfoundry_domain" "local" {
+	name = "%s"
+}
+
+data "cloudfoundry_stack" "stack" {
+	name = "%s"
+}
+
+resource "cloudfoundry_route" "dummy-app-stack" {
+  domain = "${data.cloudfoundry_domain.local.id}"
+  space = "%s"
+  hostname = "dummy-app-stack"
+}
+
+resource "cloudfoundry_app" "dummy-app-stack" {
+  name = "dummy-app-stack"
+  buildpack = "binary_buildpack"
+  space = "%s"
+  memory = "64"
+  disk_quota = "512"
+  timeout = 1800
+  strategy = "%s"
+  stack = "${data.cloudfoundry_stack.stack.id}"
+  path = "%s"
+
+  routes {
+    route = "${cloudfoundry_route.dummy-app-stack.id}"
+  }
+}
+`
+
+func TestAccResApp_stackMigration(t *testing.T) {
+
+	spaceID, _ := defaultTestSpace(t)
+
+	refApp := "cloudfoundry_app.dummy-app-stack"
+	// blue-green is done with a rolling deployment on stack change to keep app
+	for _, strategy := range []string{"none", "rolling", "blue-green"} {
+		appDeploy := &appdeployers.AppDeploy{}
+		resource.Test(t,
+			resource.TestCase{
+				PreCheck:          func() { testAccPreCheck(t) },
+				ProviderFactories: testAccProvidersFactories,
+				CheckDestroy:      testAccCheckAppDestroyed([]string{"dummy-app-stack"}),
+				Steps: []resource.TestStep{
+
+					resource.TestStep{
+						Config: fmt.Sprintf(appResourceStack,
+							defaultAppDomain(),
+							getTestDefaultStack(),
+							spaceID, spaceID,
+							strategy,
+							appPath,
+						),
+						Check: resource.ComposeTestCheckFunc(
+							testAccCheckAppExistsInject(refApp, appDeploy, func() (err error) {
+								return assertHTTPResponse("https://dummy-app-stack."+defaultAppDomain(), 200, nil)
+							}),
+							resource.TestCheckResourceAttrPair(
+								refApp, "stack", "data.cloudfoundry_stack.stack", "id"),
+						),
+					},
+
+					resource.TestStep{
+						Config: fmt.Sprintf(appResourceStack,
+							defaultAppDomain(),
+							getTestMigrationStack(),
+							spaceID, spaceID,
+							strategy,
+							appPath,
+						),
+						Check: resource.ComposeTestCheckFunc(
+							testAccCheckAppExists(refApp, func() (err error) {
+								return assertHTTPResponse("https://dummy-app-stack."+defaultAppDomain(), 200, nil)
+							}),
+							resource.TestCheckResourceAttrPair(
+								refApp, "stack", "data.cloudfoundry_stack.stack", "id"),
+							func(s *terraform.State) error {
+								rs, ok := s.RootModule().Resources[refApp]
+								if !ok {
+									return fmt.Errorf("app '%s' not found in terraform state", refApp)
+								}
+								if rs.Primary.ID != appDeploy.App.GUID {
+									return fmt.Errorf("After stack change, app must have been migrated in place but GUID has changed")
+								}
+								return nil
+							},
+						),
+					},
+				},
+			})
+	}
+}
+
+const appResourceBitsDrift = `
+
+data "cloudfoundry_domain" "local" {
+	name = "%s"
+}
+
+resource "cloudfoundry_route" "dummy-app-drift" {
+  domain = "${data.cloudfoundry_domain.local.id}"
+  space = "%s"
+  hostname = "dummy-app-drift"
+}
+
+resource "cloudfoundry_app" "dummy-app-drift" {
+  name = "dummy-app-drift"
+  buildpack = "binary_buildpack"
+  space = "%s"
+  memory = "64"
+  disk_quota = "512"
+  timeout = 1800
+  path = "%s"
+
+  routes {
+    route = "${cloudfoundry_route.dummy-app-drift.id}"
+  }
+}
+`
+
+func TestAccResApp_bitsDrift(t *testing.T) {
+
+	spaceID, _ := defaultTestSpace(t)
+
+	refApp := "cloudfoundry_app.dummy-app-drift"
+	appDeploy := &appdeployers.AppDeploy{}
+	var checksum string
+	config := fmt.Sprintf(appResourceBitsDrift,
+		defaultAppDomain(),
+		spaceID, spaceID,
+		appPath,
+	)
+	resource.Test(t,
+		resource.TestCase{
+			PreCheck:          func() { testAccPreCheck(t) },
+			ProviderFactories: testAccProvidersFactories,
+			CheckDestroy:      testAccCheckAppDestroyed([]string{"dummy-app-drift"}),
+			Steps: []resource.TestStep{
+
+				resource.TestStep{
+					Config: config,
+					Check: resource.ComposeTestCheckFunc(
+						testAccCheckAppExistsInject(refApp, appDeploy, func() (err error) {
+							checksum, err = testSession().BitsManager.GetAppBitsChecksum(appDeploy.App.GUID)
+							return err
+						}),
+						resource.TestCheckResourceAttrSet(refApp, "bits_checksum"),
+						func(s *terraform.State) error {
+							return resource.TestCheckResourceAttr(refApp, "bits_checksum", checksum)(s)
+						},
+					),
+				},
+
+				resource.TestStep{
+					// bits pushed outside of terraform must be detected and replaced by terraform ones
+					PreConfig: func() {
+						err := testSession().BitsManager.UploadApp(appDeploy.App.GUID, asset("dummy-app-rp.zip"))
+						if err != nil {
+							t.Fatal(err)
+						}
+					},
+					Config:             config,
+					PlanOnly:           true,
+					ExpectNonEmptyPlan: true,
+				},
+
+				resource.TestStep{
+					Config: config,
+					Check: resource.ComposeTestCheckFunc(
+						testAccCheckAppExistsInject(refApp, appDeploy, func() (err error) {
+							checksum, err = testSession().BitsManager.GetAppBitsChecksum(appDeploy.App.GUID)
+							if err != nil {
+								return err
+							}
+							return assertHTTPResponse("https://dummy-app-drift."+defaultAppDomain(), 200, nil)
+						}),
+						func(s *terraform.State) error {
+							return resource.TestCheckResourceAttr(refApp, "bits_checksum", checksum)(s)
+						},
+					),
+				},
+			},
+		})
+}
+
+const appResourceGit = `
+
+data "cloudfoundry_domain" "local" {
+	name = "%s"
+}
+
+resource "cloudfoundry_route" "dummy-app-git" {
+  domain = "${data.cloudfoundry_domain.local.id}"
+  space = "%s"
+  hostname = "dummy-app-git"
+}
+
+resource "cloudfoundry_app" "dummy-app-git" {
+  name = "dummy-app-git"
+  buildpack = "binary_buildpack"
+  space = "%s"
+  memory = "64"
+  disk_quota = "512"
+  timeout = 1800
+
+  git {
+    url = "%s"
+    ref = "master"
+    subdirectory = "dummy-app"
+  }
+
+  routes {
+    route = "${cloudfoundry_route.dummy-app-git.id}"
+  }
+}
+`
+
+func TestAccResApp_git(t *testing.T) {
+
+	spaceID, _ := defaultTestSpace(t)
+
+	refApp := "cloudfoundry_app.dummy-app-git"
+	repo := newTestGitRepo(t, filepath.Join(testDir(), "dummy-app"))
+	defer repo.Clean()
+	firstCommit := repo.Commit(t, nil)
+
+	var secondCommit string
+	resource.Test(t,
+		resource.TestCase{
+			PreCheck:          func() { testAccPreCheck(t) },
+			ProviderFactories: testAccProvidersFactories,
+			CheckDestroy:      testAccCheckAppDestroyed([]string{"dummy-app-git"}),
+			Steps: []resource.TestStep{
+
+				resource.TestStep{
+					Config: fmt.Sprintf(appResourceGit,
+						defaultAppDomain(),
+						spaceID, spaceID,
+						repo.BareDir,
+					),
+					Check: resource.ComposeTestCheckFunc(
+						testAccCheckAppExists(refApp, func() (err error) {
+							return assertHTTPResponse("https://dummy-app-git."+defaultAppDomain(), 200, nil)
+						}),
+						resource.TestCheckResourceAttr(
+							refApp, "git_commit", firstCommit),
+					),
+				},
+
+				resource.TestStep{
+					// a new commit on tracked branch must redeploy app without any config change
+					PreConfig: func() {
+						secondCommit = repo.Commit(t, map[string]string{"dummy-app/VERSION": "2"})
+					},
+					Config: fmt.Sprintf(appResourceGit,
+						defaultAppDomain(),
+						spaceID, spaceID,
+						repo.BareDir,
+					),
+					Check: resource.ComposeTestCheckFunc(
+						testAccCheckAppExists(refApp, func() (err error) {
+							return assertHTTPResponse("https://dummy-app-git."+defaultAppDomain(), 200, nil)
+						}),
+						func(s *terraform.State) error {
+							return resource.TestCheckResourceAttr(refApp, "git_commit", secondCommit)(s)
+						},
+					),
+				},
+			},
+		})
+}
+
 func testAccCheckAppExistsInject(resApp string, appDeploy *appdeployers.AppDeploy, validate func() error) resource.TestCheckFunc {
 	return func(s *terraform.State) error {
 		session := testAccProvider.Meta().(*managers.Session)
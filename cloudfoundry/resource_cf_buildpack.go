@@ -28,6 +28,13 @@ func resourceBuildpack() *schema.Resource {
 				Required: true,
 				ForceNew: true,
 			},
+			"stack": &schema.Schema{
+				Type:        schema.TypeString,
+				Optional:    true,
+				Computed:    true,
+				ForceNew:    true,
+				Description: "Name of the stack the buildpack is dedicated to, set by cloud foundry from buildpack manifest when not given",
+			},
 			"position": &schema.Schema{
 				Type:     schema.TypeInt,
 				Optional: true,
@@ -44,10 +51,13 @@ func resourceBuildpack() *schema.Resource {
 				Default:  false,
 			},
 			"path": &schema.Schema{
-				Type:        schema.TypeString,
-				Required:    true,
-				Description: "Path to a buildpack zip in the form of unix path or http url",
+				Type:         schema.TypeString,
+				Optional:     true,
+				Description:  "Path to a buildpack zip in the form of unix path or http url",
+				ExactlyOneOf: []string{"path", gitKey},
 			},
+			gitKey:       gitSchema("path"),
+			gitCommitKey: gitCommitSchema(),
 			"source_code_hash": {
 				Type:     schema.TypeString,
 				Optional: true,
@@ -60,6 +70,13 @@ func resourceBuildpack() *schema.Resource {
 			labelsKey:      labelsSchema(),
 			annotationsKey: annotationsSchema(),
 		},
+
+		CustomizeDiff: func(ctx context.Context, diff *schema.ResourceDiff, meta interface{}) error {
+			if diff.Id() == "" {
+				return nil
+			}
+			return gitCommitCustomizeDiff(diff)
+		},
 	}
 }
 
@@ -70,10 +87,15 @@ func resourceBuildpackCreate(ctx context.Context, d *schema.ResourceData, meta i
 	position := d.Get("position").(int)
 	locked := d.Get("locked").(bool)
 	enabled := d.Get("enabled").(bool)
-	path := d.Get("path").(string)
+	path, commit, cleanup, err := gitBitsPrepare(d)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	defer cleanup()
 
 	bp, _, err := session.ClientV2.CreateBuildpack(ccv2.Buildpack{
 		Name:     name,
+		Stack:    d.Get("stack").(string),
 		Enabled:  BoolToNullBool(enabled),
 		Locked:   BoolToNullBool(locked),
 		Position: IntToNullInt(position),
@@ -90,10 +112,12 @@ func resourceBuildpackCreate(ctx context.Context, d *schema.ResourceData, meta i
 		return diag.FromErr(err)
 	}
 	d.SetId(bp.GUID)
+	d.Set("stack", bp.Stack)
 	d.Set("position", bp.Position.Value)
 	d.Set("enabled", bp.Enabled.Value)
 	d.Set("locked", bp.Locked.Value)
 	d.Set("filename", bp.Filename)
+	d.Set(gitCommitKey, commit)
 
 	err = metadataCreate(buildpackMetadata, d, meta)
 	if err != nil {
@@ -116,6 +140,7 @@ func resourceBuildpackRead(ctx context.Context, d *schema.ResourceData, meta int
 	}
 
 	d.Set("name", bp.Name)
+	d.Set("stack", bp.Stack)
 	d.Set("position", bp.Position.Value)
 	d.Set("enabled", bp.Enabled.Value)
 	d.Set("locked", bp.Locked.Value)
@@ -151,11 +176,18 @@ func resourceBuildpackUpdate(ctx context.Context, d *schema.ResourceData, meta i
 		}
 	}
 
-	if d.HasChange("path") || d.HasChange("source_code_hash") || d.HasChange("filename") {
-		err := session.BitsManager.UploadBuildpack(d.Id(), d.Get("path").(string))
+	if d.HasChange("path") || d.HasChange("source_code_hash") || d.HasChange("filename") ||
+		d.HasChange(gitKey) || d.HasChange(gitCommitKey) {
+		path, commit, cleanup, err := gitBitsPrepare(d)
+		if err != nil {
+			return diag.FromErr(err)
+		}
+		defer cleanup()
+		err = session.BitsManager.UploadBuildpack(d.Id(), path)
 		if err != nil {
 			return diag.FromErr(err)
 		}
+		d.Set(gitCommitKey, commit)
 	}
 	err := metadataUpdate(buildpackMetadata, d, meta)
 	if err != nil {
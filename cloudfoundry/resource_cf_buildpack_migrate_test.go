@@ -10,6 +10,7 @@ import (
 )
 
 func TestBuildpackMigrateStateV0toV3(t *testing.T) {
+	testAccSkipWithoutEnvironment(t)
 	folderBits, _ = ioutil.TempDir("", "provider-cf-migrate-bp")
 	defer os.RemoveAll(folderBits)
 
@@ -0,0 +1,129 @@
+package cloudfoundry
+
+import (
+	"context"
+	"sort"
+
+	"code.cloudfoundry.org/cli/api/cloudcontroller/ccv2"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"github.com/terraform-providers/terraform-provider-cloudfoundry/cloudfoundry/managers"
+)
+
+const buildpackOrderID = "buildpack_order"
+
+func resourceBuildpackOrder() *schema.Resource {
+	return &schema.Resource{
+
+		CreateContext: resourceBuildpackOrderUpdate,
+		ReadContext:   resourceBuildpackOrderRead,
+		UpdateContext: resourceBuildpackOrderUpdate,
+		DeleteContext: resourceBuildpackOrderDelete,
+
+		Importer: &schema.ResourceImporter{
+			StateContext: ImportReadContext(resourceBuildpackOrderRead),
+		},
+
+		Schema: map[string]*schema.Schema{
+			"buildpacks": &schema.Schema{
+				Type:        schema.TypeList,
+				Required:    true,
+				MinItems:    1,
+				Description: "Buildpack GUIDs in detection priority order, buildpacks not listed are placed after them",
+				Elem: &schema.Schema{
+					Type:         schema.TypeString,
+					ValidateFunc: validation.NoZeroValues,
+				},
+			},
+		},
+	}
+}
+
+// orderedBuildpacks - retrieve all admin buildpacks sorted by their position
+func orderedBuildpacks(session *managers.Session) ([]ccv2.Buildpack, error) {
+	bps, _, err := session.ClientV2.GetBuildpacks()
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(bps, func(i, j int) bool {
+		return bps[i].Position.Value < bps[j].Position.Value
+	})
+	return bps, nil
+}
+
+func resourceBuildpackOrderRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	session := meta.(*managers.Session)
+
+	bps, err := orderedBuildpacks(session)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	// only the head of the list is owned by resource, any other buildpack
+	// inserted inside this head is shown as a drift
+	size := len(d.Get("buildpacks").([]interface{}))
+	if IsImportState(d) || size > len(bps) {
+		size = len(bps)
+	}
+	guids := make([]string, 0, size)
+	for _, bp := range bps[:size] {
+		guids = append(guids, bp.GUID)
+	}
+	d.SetId(buildpackOrderID)
+	d.Set("buildpacks", guids)
+	return nil
+}
+
+func resourceBuildpackOrderUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	session := meta.(*managers.Session)
+
+	bps, err := orderedBuildpacks(session)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	current := make(map[string]int)
+	for _, bp := range bps {
+		current[bp.GUID] = bp.Position.Value
+	}
+
+	// positions are updated one by one in the wanted order, cloud controller shifts
+	// other buildpacks when a position is taken which gives the final order at the end
+	seen := make(map[string]bool)
+	for i, guidRaw := range d.Get("buildpacks").([]interface{}) {
+		guid := guidRaw.(string)
+		if seen[guid] {
+			return diag.Errorf("Buildpack %s is listed more than once", guid)
+		}
+		seen[guid] = true
+		position := i + 1
+		pos, ok := current[guid]
+		if !ok {
+			return diag.Errorf("Buildpack %s not found", guid)
+		}
+		if pos == position {
+			continue
+		}
+		_, _, err := session.ClientV2.UpdateBuildpack(ccv2.Buildpack{
+			GUID:     guid,
+			Position: IntToNullInt(position),
+		})
+		if err != nil {
+			return diag.FromErr(err)
+		}
+		bps, err = orderedBuildpacks(session)
+		if err != nil {
+			return diag.FromErr(err)
+		}
+		for _, bp := range bps {
+			current[bp.GUID] = bp.Position.Value
+		}
+	}
+	d.SetId(buildpackOrderID)
+	return resourceBuildpackOrderRead(ctx, d, meta)
+}
+
+func resourceBuildpackOrderDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	// order is kept as it is, buildpacks are managed by their own resources
+	return nil
+}
@@ -0,0 +1,96 @@
+package cloudfoundry
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	"github.com/terraform-providers/terraform-provider-cloudfoundry/cloudfoundry/managers"
+)
+
+const buildpackOrderResource = `
+
+resource "cloudfoundry_buildpack" "bp1" {
+	name = "buildpack-order-res-1"
+	path = "%[1]s"
+}
+
+resource "cloudfoundry_buildpack" "bp2" {
+	name = "buildpack-order-res-2"
+	path = "%[1]s"
+}
+
+resource "cloudfoundry_buildpack_order" "order" {
+	buildpacks = [
+		%[2]s,
+		%[3]s,
+	]
+}
+`
+
+func TestAccResBuildpackOrder_normal(t *testing.T) {
+
+	bpPath := asset("buildpacks", "binary_buildpack-cached-v1.0.32.zip")
+	refOrder := "cloudfoundry_buildpack_order.order"
+
+	resource.Test(t,
+		resource.TestCase{
+			PreCheck:          func() { testAccPreCheck(t) },
+			ProviderFactories: testAccProvidersFactories,
+			CheckDestroy: resource.ComposeTestCheckFunc(
+				testAccCheckBuildpackDestroyed("buildpack-order-res-1"),
+				testAccCheckBuildpackDestroyed("buildpack-order-res-2"),
+			),
+			Steps: []resource.TestStep{
+
+				resource.TestStep{
+					Config: fmt.Sprintf(buildpackOrderResource, bpPath,
+						"cloudfoundry_buildpack.bp1.id", "cloudfoundry_buildpack.bp2.id"),
+					Check: resource.ComposeTestCheckFunc(
+						testAccCheckBuildpackOrder(refOrder, "buildpack-order-res-1", "buildpack-order-res-2"),
+						resource.TestCheckResourceAttr(
+							refOrder, "buildpacks.#", "2"),
+						resource.TestCheckResourceAttrPair(
+							refOrder, "buildpacks.0", "cloudfoundry_buildpack.bp1", "id"),
+					),
+				},
+
+				resource.TestStep{
+					Config: fmt.Sprintf(buildpackOrderResource, bpPath,
+						"cloudfoundry_buildpack.bp2.id", "cloudfoundry_buildpack.bp1.id"),
+					Check: resource.ComposeTestCheckFunc(
+						testAccCheckBuildpackOrder(refOrder, "buildpack-order-res-2", "buildpack-order-res-1"),
+						resource.TestCheckResourceAttrPair(
+							refOrder, "buildpacks.0", "cloudfoundry_buildpack.bp2", "id"),
+					),
+				},
+			},
+		})
+}
+
+func testAccCheckBuildpackOrder(refOrder string, names ...string) resource.TestCheckFunc {
+
+	return func(s *terraform.State) error {
+
+		session := testAccProvider.Meta().(*managers.Session)
+
+		if _, ok := s.RootModule().Resources[refOrder]; !ok {
+			return fmt.Errorf("buildpack order resource '%s' not found in terraform state", refOrder)
+		}
+
+		bps, err := orderedBuildpacks(session)
+		if err != nil {
+			return err
+		}
+		if len(bps) < len(names) {
+			return fmt.Errorf("expected at least %d buildpacks but found %d", len(names), len(bps))
+		}
+		for i, name := range names {
+			if bps[i].Name != name {
+				return fmt.Errorf("expected buildpack at position %d to be '%s' but it was '%s'", i+1, name, bps[i].Name)
+			}
+		}
+		return nil
+	}
+}
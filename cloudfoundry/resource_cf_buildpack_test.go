@@ -112,6 +112,46 @@ func TestAccResBuildpack_normal(t *testing.T) {
 		})
 }
 
+const buildpackResourceStack = `
+
+data "cloudfoundry_stack" "stack" {
+	name = "%s"
+}
+
+resource "cloudfoundry_buildpack" "binary" {
+	name = "binary-buildpack-res-stack"
+	stack = data.cloudfoundry_stack.stack.name
+
+	path = "%s"
+}
+`
+
+func TestAccResBuildpack_stack(t *testing.T) {
+
+	refBuildpack := "cloudfoundry_buildpack.binary"
+	stackName := getTestDefaultStack()
+
+	resource.ParallelTest(t,
+		resource.TestCase{
+			PreCheck:          func() { testAccPreCheck(t) },
+			ProviderFactories: testAccProvidersFactories,
+			CheckDestroy:      testAccCheckBuildpackDestroyed("binary-buildpack-res-stack"),
+			Steps: []resource.TestStep{
+
+				resource.TestStep{
+					Config: fmt.Sprintf(buildpackResourceStack, stackName, asset("buildpacks", "binary_buildpack-cached-v1.0.32.zip")),
+					Check: resource.ComposeTestCheckFunc(
+						testAccCheckBuildpackExists(refBuildpack, "binary_buildpack-cached-v1.0.32.zip"),
+						resource.TestCheckResourceAttr(
+							refBuildpack, "name", "binary-buildpack-res-stack"),
+						resource.TestCheckResourceAttr(
+							refBuildpack, "stack", stackName),
+					),
+				},
+			},
+		})
+}
+
 func testAccCheckBuildpackExists(refBuildpack, bpFilename string) resource.TestCheckFunc {
 
 	return func(s *terraform.State) error {
@@ -134,6 +174,9 @@ func testAccCheckBuildpackExists(refBuildpack, bpFilename string) resource.TestC
 		if err := assertEquals(attributes, "name", bp.Name); err != nil {
 			return err
 		}
+		if err := assertEquals(attributes, "stack", bp.Stack); err != nil {
+			return err
+		}
 		if err := assertEquals(attributes, "position", bp.Position.Value); err != nil {
 			return err
 		}
@@ -60,8 +60,10 @@ func resourceOrg() *schema.Resource {
 				Elem:       &schema.Schema{Type: schema.TypeString},
 				Set:        resourceStringHash,
 			},
-			labelsKey:      labelsSchema(),
-			annotationsKey: annotationsSchema(),
+			labelsKey:             labelsSchema(),
+			annotationsKey:        annotationsSchema(),
+			"deletion_protection": deletionProtectionSchema(),
+			"delete_recursive":    deleteRecursiveSchema(),
 		},
 	}
 }
@@ -101,6 +103,7 @@ func resourceOrgRead(ctx context.Context, d *schema.ResourceData, meta interface
 
 	d.Set("name", org.Name)
 	d.Set("quota", org.QuotaDefinitionGUID)
+	deletionProtectionRead(d)
 
 	for t, r := range orgRoleMap {
 		users, _, err := om.GetOrganizationUsersByRole(r, id)
@@ -173,26 +176,21 @@ func resourceOrgDelete(ctx context.Context, d *schema.ResourceData, meta interfa
 	client := session.ClientV2
 
 	id := d.Id()
+	err := deletionProtectionCheck(session, d, "org", "organization_guids="+id)
+	if err != nil {
+		return diag.FromErr(err)
+	}
 	spaces, _, err := client.GetSpaces(ccv2.FilterByOrg(id))
-
 	if err != nil {
 		return diag.FromErr(err)
 	}
 	for _, s := range spaces {
-		j, _, err := client.DeleteSpace(s.GUID)
-		if err != nil {
-			return diag.FromErr(err)
-		}
-		_, err = client.PollJob(j)
+		err = deleteContainer(session, d, "/v2/spaces/"+s.GUID)
 		if err != nil {
 			return diag.FromErr(err)
 		}
 	}
-	j, _, err := client.DeleteOrganization(id)
-	if err != nil {
-		return diag.FromErr(err)
-	}
-	_, err = client.PollJob(j)
+	err = deleteContainer(session, d, "/v2/organizations/"+id)
 	if err != nil {
 		return diag.FromErr(err)
 	}
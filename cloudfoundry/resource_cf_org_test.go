@@ -4,6 +4,7 @@ import (
 	"code.cloudfoundry.org/cli/api/cloudcontroller/ccv2"
 	"fmt"
 	"github.com/terraform-providers/terraform-provider-cloudfoundry/cloudfoundry/managers"
+	"regexp"
 	"testing"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
@@ -146,6 +147,56 @@ func TestAccResOrg_normal(t *testing.T) {
 		})
 }
 
+const orgResourceProtected = `
+resource "cloudfoundry_org" "protected" {
+	name = "organization-protected"
+}
+`
+
+const orgResourceRecursive = `
+resource "cloudfoundry_org" "protected" {
+	name = "organization-protected"
+	delete_recursive = true
+}
+`
+
+func TestAccResOrg_deletionProtection(t *testing.T) {
+
+	ref := "cloudfoundry_org.protected"
+
+	resource.Test(t,
+		resource.TestCase{
+			PreCheck:          func() { testAccPreCheck(t) },
+			ProviderFactories: testAccProvidersFactories,
+			CheckDestroy:      testAccCheckOrgDestroyed("organization-protected"),
+			Steps: []resource.TestStep{
+
+				resource.TestStep{
+					Config: orgResourceProtected,
+					Check: resource.ComposeTestCheckFunc(
+						resource.TestCheckResourceAttr(
+							ref, "deletion_protection", "true"),
+						testAccCreateSpaceInOrg(ref, "space-in-protected-org", "app-in-protected-org"),
+					),
+				},
+
+				resource.TestStep{
+					Config:      orgResourceProtected,
+					Destroy:     true,
+					ExpectError: regexp.MustCompile(`(?s)org 'organization-protected' is not empty.*apps \(1\): app-in-protected-org`),
+				},
+
+				resource.TestStep{
+					Config: orgResourceRecursive,
+					Check: resource.ComposeTestCheckFunc(
+						resource.TestCheckResourceAttr(
+							ref, "delete_recursive", "true"),
+					),
+				},
+			},
+		})
+}
+
 func testAccCheckOrgExists(resOrg, resQuota string, refUserRemoved *string) resource.TestCheckFunc {
 
 	return func(s *terraform.State) (err error) {
@@ -242,3 +293,34 @@ func testUserRemovedFromOrg(
 	}
 	return nil
 }
+
+// testAccCreateSpaceInOrg - create a space holding an app, both unknown to terraform, in org of resource
+func testAccCreateSpaceInOrg(resource string, spaceName string, appName string) resource.TestCheckFunc {
+
+	return func(s *terraform.State) error {
+
+		rs, ok := s.RootModule().Resources[resource]
+		if !ok {
+			return fmt.Errorf("org '%s' not found in terraform state", resource)
+		}
+		var space struct {
+			GUID string `json:"guid"`
+		}
+		_, err := v3Do(testSession(), "POST", "/v3/spaces", map[string]interface{}{
+			"name": spaceName,
+			"relationships": map[string]interface{}{
+				"organization": newV3Relationship(rs.Primary.ID),
+			},
+		}, &space)
+		if err != nil {
+			return err
+		}
+		_, err = v3Do(testSession(), "POST", "/v3/apps", map[string]interface{}{
+			"name": appName,
+			"relationships": map[string]interface{}{
+				"space": newV3Relationship(space.GUID),
+			},
+		}, nil)
+		return err
+	}
+}
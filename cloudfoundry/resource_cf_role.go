@@ -0,0 +1,200 @@
+package cloudfoundry
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"github.com/terraform-providers/terraform-provider-cloudfoundry/cloudfoundry/managers"
+)
+
+// roleTypes - role types of cloud controller v3 api with kind of entity they apply to
+var roleTypes = map[string]string{
+	"organization_user":            "org",
+	"organization_auditor":         "org",
+	"organization_manager":         "org",
+	"organization_billing_manager": "org",
+	"space_auditor":                "space",
+	"space_developer":              "space",
+	"space_manager":                "space",
+	"space_supporter":              "space",
+}
+
+const defaultUserOrigin = "uaa"
+
+func resourceRole() *schema.Resource {
+	types := make([]string, 0, len(roleTypes))
+	for t := range roleTypes {
+		types = append(types, t)
+	}
+
+	return &schema.Resource{
+		CreateContext: resourceRoleCreate,
+		ReadContext:   resourceRoleRead,
+		DeleteContext: resourceRoleDelete,
+
+		Importer: &schema.ResourceImporter{
+			StateContext: ImportReadContext(resourceRoleRead),
+		},
+
+		Timeouts: &schema.ResourceTimeout{
+			Delete: schema.DefaultTimeout(5 * time.Minute),
+		},
+
+		Schema: map[string]*schema.Schema{
+			"type": &schema.Schema{
+				Type:         schema.TypeString,
+				Required:     true,
+				ForceNew:     true,
+				ValidateFunc: validation.StringInSlice(types, false),
+			},
+			"user": &schema.Schema{
+				Type:         schema.TypeString,
+				Optional:     true,
+				Computed:     true,
+				ForceNew:     true,
+				Description:  "User GUID",
+				ExactlyOneOf: []string{"user", "username"},
+			},
+			"username": &schema.Schema{
+				Type:         schema.TypeString,
+				Optional:     true,
+				Computed:     true,
+				ForceNew:     true,
+				ExactlyOneOf: []string{"user", "username"},
+			},
+			"origin": &schema.Schema{
+				Type:          schema.TypeString,
+				Optional:      true,
+				Computed:      true,
+				ForceNew:      true,
+				Description:   "Origin of user given by username, default to uaa",
+				ConflictsWith: []string{"user"},
+			},
+			"org": &schema.Schema{
+				Type:         schema.TypeString,
+				Optional:     true,
+				ForceNew:     true,
+				ExactlyOneOf: []string{"org", "space"},
+			},
+			"space": &schema.Schema{
+				Type:         schema.TypeString,
+				Optional:     true,
+				ForceNew:     true,
+				ExactlyOneOf: []string{"org", "space"},
+			},
+		},
+
+		CustomizeDiff: func(ctx context.Context, diff *schema.ResourceDiff, meta interface{}) error {
+			return validateRoleTarget(diff.Get("type").(string), diff.Get("org").(string), diff.Get("space").(string))
+		},
+	}
+}
+
+// v3Role - role as given by cloud controller v3 api
+type v3Role struct {
+	GUID          string `json:"guid"`
+	Type          string `json:"type"`
+	Relationships struct {
+		User         *v3Relationship `json:"user"`
+		Organization *v3Relationship `json:"organization"`
+		Space        *v3Relationship `json:"space"`
+	} `json:"relationships"`
+	Included struct {
+		Users []struct {
+			GUID     string `json:"guid"`
+			Username string `json:"username"`
+			Origin   string `json:"origin"`
+		} `json:"users"`
+	} `json:"included"`
+}
+
+func resourceRoleCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	session := meta.(*managers.Session)
+
+	roleType := d.Get("type").(string)
+	// user is given by guid or by username and origin, cloud controller looks up its guid in uaa
+	user := map[string]interface{}{}
+	if guid := d.Get("user").(string); guid != "" {
+		user["guid"] = guid
+	} else {
+		origin := d.Get("origin").(string)
+		if origin == "" {
+			origin = defaultUserOrigin
+		}
+		user["username"] = d.Get("username").(string)
+		user["origin"] = origin
+	}
+	relationships := map[string]interface{}{
+		"user": map[string]interface{}{"data": user},
+	}
+	if roleTypes[roleType] == "org" {
+		relationships["organization"] = newV3Relationship(d.Get("org").(string))
+	} else {
+		relationships["space"] = newV3Relationship(d.Get("space").(string))
+	}
+
+	var role v3Role
+	_, err := v3Do(session, "POST", "/v3/roles", map[string]interface{}{
+		"type":          roleType,
+		"relationships": relationships,
+	}, &role)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	d.SetId(role.GUID)
+	return resourceRoleRead(ctx, d, meta)
+}
+
+func resourceRoleRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	session := meta.(*managers.Session)
+
+	var role v3Role
+	_, err := v3Do(session, "GET", fmt.Sprintf("/v3/roles/%s?include=user", d.Id()), nil, &role)
+	if err != nil {
+		if IsErrNotFound(err) {
+			d.SetId("")
+			return nil
+		}
+		return diag.FromErr(err)
+	}
+	d.Set("type", role.Type)
+	d.Set("user", role.Relationships.User.GUID())
+	d.Set("org", role.Relationships.Organization.GUID())
+	d.Set("space", role.Relationships.Space.GUID())
+	for _, u := range role.Included.Users {
+		if u.GUID != role.Relationships.User.GUID() {
+			continue
+		}
+		// username given in a different case in config is kept, uaa usernames are case insensitive
+		if !strings.EqualFold(d.Get("username").(string), u.Username) {
+			d.Set("username", u.Username)
+		}
+		d.Set("origin", u.Origin)
+	}
+	return nil
+}
+
+func resourceRoleDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	session := meta.(*managers.Session)
+
+	jobPath, err := v3Do(session, "DELETE", fmt.Sprintf("/v3/roles/%s", d.Id()), nil, nil)
+	if err != nil {
+		if IsErrNotFound(err) {
+			return nil
+		}
+		return diag.FromErr(err)
+	}
+	if jobPath == "" {
+		return nil
+	}
+	job, err := v3PollJob(ctx, session, jobPath, d.Timeout(schema.TimeoutDelete))
+	if err != nil {
+		return append(job.WarningsDiags(), diag.FromErr(err)...)
+	}
+	return job.WarningsDiags()
+}
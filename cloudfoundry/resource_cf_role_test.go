@@ -0,0 +1,135 @@
+package cloudfoundry
+
+import (
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+const roleResource = `
+resource "cloudfoundry_user" "role-user" {
+  name = "role-user@acme.com"
+  password = "password"
+}
+
+resource "cloudfoundry_role" "org-user" {
+  type = "organization_user"
+  user = cloudfoundry_user.role-user.id
+  org = "%s"
+}
+
+resource "cloudfoundry_role" "space-supporter" {
+  depends_on = [cloudfoundry_role.org-user]
+  type = "space_supporter"
+  username = "role-user@acme.com"
+  space = "%s"
+}
+`
+
+const roleResourceInvalid = `
+resource "cloudfoundry_role" "invalid" {
+  type = "space_developer"
+  user = "a-guid"
+  org = "%s"
+}
+`
+
+func TestAccResRole_normal(t *testing.T) {
+	orgID, _ := defaultTestOrg(t)
+	spaceID, _ := defaultTestSpace(t)
+
+	refOrg := "cloudfoundry_role.org-user"
+	refSpace := "cloudfoundry_role.space-supporter"
+	resource.Test(t,
+		resource.TestCase{
+			PreCheck:          func() { testAccPreCheck(t) },
+			ProviderFactories: testAccProvidersFactories,
+			CheckDestroy: resource.ComposeTestCheckFunc(
+				testAccCheckRoleDestroyed(refOrg),
+				testAccCheckRoleDestroyed(refSpace),
+			),
+			Steps: []resource.TestStep{
+
+				resource.TestStep{
+					Config: fmt.Sprintf(roleResource, orgID, spaceID),
+					Check: resource.ComposeTestCheckFunc(
+						testAccCheckRoleExists(refOrg),
+						testAccCheckRoleExists(refSpace),
+						resource.TestCheckResourceAttr(refOrg, "type", "organization_user"),
+						resource.TestCheckResourceAttr(refOrg, "org", orgID),
+						resource.TestCheckResourceAttr(refOrg, "username", "role-user@acme.com"),
+						resource.TestCheckResourceAttr(refOrg, "origin", "uaa"),
+						resource.TestCheckResourceAttr(refSpace, "type", "space_supporter"),
+						resource.TestCheckResourceAttr(refSpace, "space", spaceID),
+						resource.TestCheckResourceAttrPair(refSpace, "user", "cloudfoundry_user.role-user", "id"),
+						resource.TestCheckResourceAttr(refSpace, "origin", "uaa"),
+					),
+				},
+
+				resource.TestStep{
+					ResourceName:      refSpace,
+					ImportState:       true,
+					ImportStateVerify: true,
+				},
+			},
+		})
+}
+
+func TestAccResRole_invalidTarget(t *testing.T) {
+	orgID, _ := defaultTestOrg(t)
+
+	resource.Test(t,
+		resource.TestCase{
+			PreCheck:          func() { testAccPreCheck(t) },
+			ProviderFactories: testAccProvidersFactories,
+			Steps: []resource.TestStep{
+
+				resource.TestStep{
+					Config:      fmt.Sprintf(roleResourceInvalid, orgID),
+					PlanOnly:    true,
+					ExpectError: regexp.MustCompile("space must be set instead of org"),
+				},
+			},
+		})
+}
+
+func testAccCheckRoleExists(resource string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources[resource]
+		if !ok {
+			return fmt.Errorf("role '%s' not found in terraform state", resource)
+		}
+		var role v3Role
+		_, err := v3Do(testSession(), "GET", fmt.Sprintf("/v3/roles/%s", rs.Primary.ID), nil, &role)
+		if err != nil {
+			return err
+		}
+		if role.Type != rs.Primary.Attributes["type"] {
+			return fmt.Errorf("expected role of type '%s' but found '%s'", rs.Primary.Attributes["type"], role.Type)
+		}
+		if role.Relationships.User.GUID() != rs.Primary.Attributes["user"] {
+			return fmt.Errorf("expected role for user '%s' but found '%s'", rs.Primary.Attributes["user"], role.Relationships.User.GUID())
+		}
+		return nil
+	}
+}
+
+func testAccCheckRoleDestroyed(resource string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources[resource]
+		if !ok {
+			return nil
+		}
+		_, err := v3Do(testSession(), "GET", fmt.Sprintf("/v3/roles/%s", rs.Primary.ID), nil, nil)
+		if err == nil {
+			return fmt.Errorf("role %s still exists", rs.Primary.ID)
+		}
+		if !IsErrNotFound(err) {
+			return err
+		}
+		return nil
+	}
+}
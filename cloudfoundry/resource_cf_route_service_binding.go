@@ -2,12 +2,15 @@ package cloudfoundry
 
 import (
 	"context"
-	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
-	"github.com/terraform-providers/terraform-provider-cloudfoundry/cloudfoundry/managers"
-
 	"encoding/json"
+	"fmt"
+	"time"
 
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/structure"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"github.com/terraform-providers/terraform-provider-cloudfoundry/cloudfoundry/managers"
 )
 
 func resourceRouteServiceBinding() *schema.Resource {
@@ -15,12 +18,19 @@ func resourceRouteServiceBinding() *schema.Resource {
 	return &schema.Resource{
 		CreateContext: resourceRouteServiceBindingCreate,
 		ReadContext:   resourceRouteServiceBindingRead,
+		UpdateContext: resourceRouteServiceBindingUpdate,
 		DeleteContext: resourceRouteServiceBindingDelete,
 
 		Importer: &schema.ResourceImporter{
 			StateContext: resourceRouteServiceBindingImport,
 		},
 
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(15 * time.Minute),
+			Update: schema.DefaultTimeout(15 * time.Minute),
+			Delete: schema.DefaultTimeout(15 * time.Minute),
+		},
+
 		Schema: map[string]*schema.Schema{
 			"service_instance": &schema.Schema{
 				Type:     schema.TypeString,
@@ -28,83 +38,322 @@ func resourceRouteServiceBinding() *schema.Resource {
 				ForceNew: true,
 			},
 			"route": &schema.Schema{
-				Type:     schema.TypeString,
-				Required: true,
-				ForceNew: true,
+				Type:         schema.TypeString,
+				Optional:     true,
+				ForceNew:     true,
+				ExactlyOneOf: []string{"route", "routes"},
+			},
+			"routes": &schema.Schema{
+				Type:         schema.TypeSet,
+				Optional:     true,
+				Description:  "Routes bound to service instance, any other route bound to it is unbound",
+				Elem:         &schema.Schema{Type: schema.TypeString},
+				Set:          schema.HashString,
+				ExactlyOneOf: []string{"route", "routes"},
 			},
 			"json_params": &schema.Schema{
+				Type:             schema.TypeString,
+				Optional:         true,
+				ForceNew:         true,
+				ValidateFunc:     validation.StringIsJSON,
+				DiffSuppressFunc: structure.SuppressJsonDiff,
+			},
+			"route_service_url": &schema.Schema{
 				Type:     schema.TypeString,
-				Optional: true,
-				ForceNew: true,
+				Computed: true,
 			},
 		},
+
+		CustomizeDiff: func(ctx context.Context, diff *schema.ResourceDiff, meta interface{}) error {
+			if diff.Id() != "" && !diff.HasChange("json_params") {
+				return nil
+			}
+			return bindingParamsCustomizeDiff(diff, meta.(*managers.Session), "service_instance", "", "json_params")
+		},
 	}
 }
 
+// v3RouteBinding - service route binding as given by cloud controller v3 api
+type v3RouteBinding struct {
+	GUID            string `json:"guid"`
+	RouteServiceURL string `json:"route_service_url"`
+	LastOperation   struct {
+		Type        string `json:"type"`
+		State       string `json:"state"`
+		Description string `json:"description"`
+	} `json:"last_operation"`
+	Relationships struct {
+		Route           *v3Relationship `json:"route"`
+		ServiceInstance *v3Relationship `json:"service_instance"`
+	} `json:"relationships"`
+}
+
+// getV3RouteBindings - route bindings of a service instance keyed by route guid
+func getV3RouteBindings(session *managers.Session, serviceInstanceGUID string) (map[string]v3RouteBinding, error) {
+	bindings := make(map[string]v3RouteBinding)
+	path := fmt.Sprintf("/v3/service_route_bindings?service_instance_guids=%s", serviceInstanceGUID)
+	err := v3GetAll(session, path, func(resources json.RawMessage) error {
+		var page []v3RouteBinding
+		err := json.Unmarshal(resources, &page)
+		if err != nil {
+			return err
+		}
+		for _, b := range page {
+			bindings[b.Relationships.Route.GUID()] = b
+		}
+		return nil
+	})
+	return bindings, err
+}
+
+// routeBindingMultiple - resource manages all routes of service instance, id is then service instance guid only
+func routeBindingMultiple(d *schema.ResourceData) bool {
+	_, _, err := parseID(d.Id())
+	return err != nil
+}
+
 func resourceRouteServiceBindingImport(ctx context.Context, d *schema.ResourceData, meta interface{}) (res []*schema.ResourceData, err error) {
-	id := d.Id()
-	if _, _, err = parseID(id); err != nil {
-		return
-	}
 	return ImportReadContext(resourceRouteServiceBindingRead)(ctx, d, meta)
 }
 
+// createV3RouteBinding - bind a route to service instance and wait broker to finish when asynchronous
+func createV3RouteBinding(ctx context.Context, session *managers.Session, serviceID, routeID string, params map[string]interface{}, timeout time.Duration) diag.Diagnostics {
+	payload := map[string]interface{}{
+		"relationships": map[string]interface{}{
+			"route":            newV3Relationship(routeID),
+			"service_instance": newV3Relationship(serviceID),
+		},
+	}
+	if len(params) > 0 {
+		payload["parameters"] = params
+	}
+	jobPath, err := v3Do(session, "POST", "/v3/service_route_bindings", payload, nil)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	if jobPath == "" {
+		return nil
+	}
+	job, err := v3PollJob(ctx, session, jobPath, timeout)
+	if err != nil {
+		return routeBindingOperationDiags(session, serviceID, routeID, job, err)
+	}
+	return job.WarningsDiags()
+}
+
+func deleteV3RouteBinding(ctx context.Context, session *managers.Session, binding v3RouteBinding, timeout time.Duration) diag.Diagnostics {
+	jobPath, err := v3Do(session, "DELETE", fmt.Sprintf("/v3/service_route_bindings/%s", binding.GUID), nil, nil)
+	if err != nil {
+		if IsErrNotFound(err) {
+			return nil
+		}
+		return diag.FromErr(err)
+	}
+	if jobPath == "" {
+		return nil
+	}
+	job, err := v3PollJob(ctx, session, jobPath, timeout)
+	if err != nil {
+		return routeBindingOperationDiags(session, binding.Relationships.ServiceInstance.GUID(), binding.Relationships.Route.GUID(), job, err)
+	}
+	return job.WarningsDiags()
+}
+
+// routeBindingOperationDiags - turn a failed operation on route binding into diagnostics,
+// description of last operation given by broker is added as detail when available
+func routeBindingOperationDiags(session *managers.Session, serviceID, routeID string, job v3Job, err error) diag.Diagnostics {
+	diags := job.WarningsDiags()
+	errDiag := diag.Diagnostic{
+		Severity: diag.Error,
+		Summary:  fmt.Sprintf("Route %s binding to service instance %s failed: %s", routeID, serviceID, err.Error()),
+	}
+	bindings, errGet := getV3RouteBindings(session, serviceID)
+	if b, ok := bindings[routeID]; errGet == nil && ok && b.LastOperation.Description != "" {
+		errDiag.Detail = fmt.Sprintf("Last operation %s is %s: %s",
+			b.LastOperation.Type, b.LastOperation.State, b.LastOperation.Description)
+	}
+	return append(diags, errDiag)
+}
+
+func routeBindingParams(d *schema.ResourceData) (map[string]interface{}, error) {
+	var params map[string]interface{}
+	if raw := d.Get("json_params").(string); raw != "" {
+		if err := json.Unmarshal([]byte(raw), &params); err != nil {
+			return nil, err
+		}
+	}
+	return params, nil
+}
+
 func resourceRouteServiceBindingCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	session := meta.(*managers.Session)
 
-	var data map[string]interface{}
-
 	serviceID := d.Get("service_instance").(string)
-	routeID := d.Get("route").(string)
-	params, okParams := d.GetOk("json_params")
+	params, err := routeBindingParams(d)
+	if err != nil {
+		return diag.FromErr(err)
+	}
 
-	if okParams {
-		if err := json.Unmarshal([]byte(params.(string)), &data); err != nil {
-			return diag.FromErr(err)
+	if routeID := d.Get("route").(string); routeID != "" {
+		diags := createV3RouteBinding(ctx, session, serviceID, routeID, params, d.Timeout(schema.TimeoutCreate))
+		if diags.HasError() {
+			return diags
 		}
+		d.SetId(computeID(serviceID, routeID))
+		return append(diags, resourceRouteServiceBindingRead(ctx, d, meta)...)
+	}
+
+	d.SetId(serviceID)
+	return resourceRouteServiceBindingUpdate(ctx, d, meta)
+}
+
+func resourceRouteServiceBindingRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	session := meta.(*managers.Session)
+
+	serviceID := d.Id()
+	routeID := ""
+	if !routeBindingMultiple(d) {
+		serviceID, routeID, _ = parseID(d.Id())
 	}
-	_, err := session.ClientV2.CreateServiceBindingRoute(serviceID, routeID, data)
+	bindings, err := getV3RouteBindings(session, serviceID)
 	if err != nil {
+		if IsErrNotFound(err) {
+			d.SetId("")
+			return nil
+		}
 		return diag.FromErr(err)
 	}
 
-	d.SetId(computeID(serviceID, routeID))
+	var binding v3RouteBinding
+	if routeID != "" {
+		var ok bool
+		binding, ok = bindings[routeID]
+		if !ok {
+			d.SetId("")
+			return nil
+		}
+		d.Set("route", routeID)
+	} else {
+		routes := make([]string, 0, len(bindings))
+		for route, b := range bindings {
+			routes = append(routes, route)
+			binding = b
+		}
+		d.Set("routes", routes)
+	}
+	d.Set("service_instance", serviceID)
+	d.Set("route_service_url", binding.RouteServiceURL)
+	if binding.GUID == "" {
+		return nil
+	}
+
+	retrievable, err := routeBindingParamsRetrievable(session, serviceID)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	if !retrievable {
+		return nil
+	}
+	var params map[string]interface{}
+	_, err = v3Do(session, "GET", fmt.Sprintf("/v3/service_route_bindings/%s/parameters", binding.GUID), nil, &params)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	if len(params) == 0 && d.Get("json_params").(string) == "" {
+		return nil
+	}
+	b, err := json.Marshal(params)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	d.Set("json_params", string(b))
 	return nil
 }
 
-func resourceRouteServiceBindingRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+// routeBindingParamsRetrievable - check if broker of service instance let params of bindings be fetched,
+// user provided service instance has no broker and no params
+func routeBindingParamsRetrievable(session *managers.Session, serviceID string) (bool, error) {
+	si, err := getV3ServiceInstance(session, serviceID)
+	if err != nil {
+		return false, err
+	}
+	planGUID := si.Relationships.ServicePlan.GUID()
+	if planGUID == "" {
+		return false, nil
+	}
+	var plan struct {
+		Included struct {
+			ServiceOfferings []v3ServiceOffering `json:"service_offerings"`
+		} `json:"included"`
+	}
+	_, err = v3Do(session, "GET", fmt.Sprintf("/v3/service_plans/%s?include=service_offering", planGUID), nil, &plan)
+	if err != nil {
+		if IsErrNotFound(err) || IsErrNotAuthorized(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	offerings := plan.Included.ServiceOfferings
+	return len(offerings) > 0 && offerings[0].BrokerCatalog.Features.BindingsRetrievable, nil
+}
+
+func resourceRouteServiceBindingUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	session := meta.(*managers.Session)
 
-	serviceID, routeID, err := parseID(d.Id())
+	serviceID := d.Get("service_instance").(string)
+	params, err := routeBindingParams(d)
 	if err != nil {
 		return diag.FromErr(err)
 	}
-	routes, _, err := session.ClientV2.GetServiceBindingRoutes(serviceID)
+	current, err := getV3RouteBindings(session, serviceID)
 	if err != nil {
 		return diag.FromErr(err)
 	}
-	found := false
-	for _, route := range routes {
-		if route.GUID == routeID {
-			found = true
-			break
+	wanted := d.Get("routes").(*schema.Set)
+
+	var diags diag.Diagnostics
+	// routes bound outside of terraform are reconciled too
+	for route, binding := range current {
+		if wanted.Contains(route) {
+			continue
+		}
+		diags = append(diags, deleteV3RouteBinding(ctx, session, binding, d.Timeout(schema.TimeoutUpdate))...)
+		if diags.HasError() {
+			return diags
 		}
 	}
-	if !found {
-		d.SetId("")
-		return diag.Errorf("Route '%s' not found in service instance '%s'", routeID, serviceID)
+	for _, r := range wanted.List() {
+		if _, ok := current[r.(string)]; ok {
+			continue
+		}
+		diags = append(diags, createV3RouteBinding(ctx, session, serviceID, r.(string), params, d.Timeout(schema.TimeoutUpdate))...)
+		if diags.HasError() {
+			return append(diags, resourceRouteServiceBindingRead(ctx, d, meta)...)
+		}
 	}
-
-	d.Set("service_instance", serviceID)
-	d.Set("route", routeID)
-	return nil
+	return append(diags, resourceRouteServiceBindingRead(ctx, d, meta)...)
 }
 
 func resourceRouteServiceBindingDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	session := meta.(*managers.Session)
 
 	serviceID := d.Get("service_instance").(string)
-	routeID := d.Get("route").(string)
-	_, err := session.ClientV2.DeleteServiceBindingRoute(serviceID, routeID)
-	return diag.FromErr(err)
+	bindings, err := getV3RouteBindings(session, serviceID)
+	if err != nil {
+		if IsErrNotFound(err) {
+			return nil
+		}
+		return diag.FromErr(err)
+	}
+	var diags diag.Diagnostics
+	for route, binding := range bindings {
+		if !routeBindingMultiple(d) && route != d.Get("route").(string) {
+			continue
+		}
+		diags = append(diags, deleteV3RouteBinding(ctx, session, binding, d.Timeout(schema.TimeoutDelete))...)
+		if diags.HasError() {
+			return diags
+		}
+	}
+	return diags
 }
@@ -131,6 +131,22 @@ resource "cloudfoundry_route_service_binding" "route-bind" {
 }
 `
 
+const routeBindingResourceMany = `
+resource "cloudfoundry_route" "dummy-app-other" {
+  domain = "${data.cloudfoundry_domain.local.id}"
+  space = "${data.cloudfoundry_space.space.id}"
+  hostname = "dummy-app-other"
+}
+
+resource "cloudfoundry_route_service_binding" "route-bind-many" {
+  service_instance = "${cloudfoundry_service_instance.basic-auth.id}"
+  routes = [
+    "${cloudfoundry_route.dummy-app.id}",
+    "${cloudfoundry_route.dummy-app-other.id}",
+  ]
+}
+`
+
 const routeBindingResourceDelete = `
 resource "cloudfoundry_route" "dummy-app-other" {
   domain = "${data.cloudfoundry_domain.local.id}"
@@ -144,6 +160,7 @@ func TestAccResRouteServiceBinding_normal(t *testing.T) {
 	_, spaceName := defaultTestSpace(t)
 
 	ref := "cloudfoundry_route_service_binding.route-bind"
+	refMany := "cloudfoundry_route_service_binding.route-bind-many"
 	tpl, _ := template.New("sql").Parse(routeBindingResourceCommon)
 	buf := &bytes.Buffer{}
 	err := tpl.Execute(buf, map[string]interface{}{
@@ -182,6 +199,29 @@ func TestAccResRouteServiceBinding_normal(t *testing.T) {
 						checkAppResponse(appURL, 200),
 					),
 				},
+				resource.TestStep{
+					// route-bind is removed on its own, route-bind-many binds the same route and must not race with it
+					Config: buf.String() + routeBindingResourceDelete,
+					Check: resource.ComposeTestCheckFunc(
+						checkRouteServiceBinding("cloudfoundry_service_instance.basic-auth", "cloudfoundry_route.dummy-app-other", false),
+					),
+				},
+				resource.TestStep{
+					Config: buf.String() + routeBindingResourceMany,
+					Check: resource.ComposeTestCheckFunc(
+						resource.TestCheckResourceAttrPair(refMany, "id", "cloudfoundry_service_instance.basic-auth", "id"),
+						resource.TestCheckResourceAttr(refMany, "routes.#", "2"),
+						resource.TestCheckResourceAttr(refMany, "route_service_url", "https://basic-auth-router."+defaultAppDomain()),
+						checkRouteServiceBinding("cloudfoundry_service_instance.basic-auth", "cloudfoundry_route.dummy-app", true),
+						checkRouteServiceBinding("cloudfoundry_service_instance.basic-auth", "cloudfoundry_route.dummy-app-other", true),
+						checkAppResponse(appURL, 401),
+					),
+				},
+				resource.TestStep{
+					ResourceName:      refMany,
+					ImportState:       true,
+					ImportStateVerify: true,
+				},
 				resource.TestStep{
 					Config: buf.String() + routeBindingResourceDelete,
 					Check: resource.ComposeTestCheckFunc(
@@ -227,17 +267,11 @@ func checkRouteServiceBinding(serviceName, routeName string, exists bool) resour
 		}
 		serviceID := service.Primary.ID
 		routeID := route.Primary.ID
-		routes, _, err := session.ClientV2.GetServiceBindingRoutes(serviceID)
+		bindings, err := getV3RouteBindings(session, serviceID)
 		if err != nil {
 			return err
 		}
-		found := false
-		for _, route := range routes {
-			if route.GUID == routeID {
-				found = true
-				break
-			}
-		}
+		_, found := bindings[routeID]
 		if !found && exists {
 			return fmt.Errorf("unable to find route '%s(%s)' binding to service '%s(%s)'", serviceName, serviceID, routeName, routeID)
 		}
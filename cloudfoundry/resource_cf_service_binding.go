@@ -0,0 +1,303 @@
+package cloudfoundry
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"code.cloudfoundry.org/cli/api/cloudcontroller/ccv2/constant"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"github.com/terraform-providers/terraform-provider-cloudfoundry/cloudfoundry/managers"
+	"github.com/terraform-providers/terraform-provider-cloudfoundry/cloudfoundry/managers/appdeployers"
+)
+
+func resourceServiceBinding() *schema.Resource {
+
+	return &schema.Resource{
+
+		CreateContext: resourceServiceBindingCreate,
+		ReadContext:   resourceServiceBindingRead,
+		UpdateContext: resourceServiceBindingUpdate,
+		DeleteContext: resourceServiceBindingDelete,
+
+		Importer: &schema.ResourceImporter{
+			StateContext: ImportReadContext(resourceServiceBindingRead),
+		},
+
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(15 * time.Minute),
+			Delete: schema.DefaultTimeout(15 * time.Minute),
+		},
+
+		Schema: map[string]*schema.Schema{
+
+			"service_instance": &schema.Schema{
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+			"app": &schema.Schema{
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+			"name": &schema.Schema{
+				Type:     schema.TypeString,
+				Optional: true,
+				ForceNew: true,
+			},
+			"params": &schema.Schema{
+				Type:          schema.TypeMap,
+				Optional:      true,
+				ForceNew:      true,
+				Sensitive:     true,
+				ConflictsWith: []string{"params_json"},
+			},
+			"params_json": &schema.Schema{
+				Type:          schema.TypeString,
+				Optional:      true,
+				ForceNew:      true,
+				Sensitive:     true,
+				ConflictsWith: []string{"params"},
+				ValidateFunc:  validation.StringIsJSON,
+			},
+			"restart_app": &schema.Schema{
+				Type:          schema.TypeBool,
+				Optional:      true,
+				Default:       false,
+				Description:   "Restart app when binding is created or deleted to let it take binding",
+				ConflictsWith: []string{"restage_app"},
+			},
+			"restage_app": &schema.Schema{
+				Type:          schema.TypeBool,
+				Optional:      true,
+				Default:       false,
+				Description:   "Restage app when binding is created or deleted to let it take binding",
+				ConflictsWith: []string{"restart_app"},
+			},
+			"credentials": &schema.Schema{
+				Type:      schema.TypeMap,
+				Computed:  true,
+				Sensitive: true,
+			},
+		},
+
+		CustomizeDiff: func(ctx context.Context, diff *schema.ResourceDiff, meta interface{}) error {
+			if diff.Id() != "" && !diff.HasChange("params") && !diff.HasChange("params_json") && !diff.HasChange("service_instance") {
+				return nil
+			}
+			return bindingParamsCustomizeDiff(diff, meta.(*managers.Session), "service_instance", "params", "params_json")
+		},
+	}
+}
+
+// v3ServiceCredentialBinding - service credential binding as given by cloud controller v3 api
+type v3ServiceCredentialBinding struct {
+	GUID          string `json:"guid"`
+	Name          string `json:"name"`
+	Type          string `json:"type"`
+	LastOperation struct {
+		Type        string `json:"type"`
+		State       string `json:"state"`
+		Description string `json:"description"`
+	} `json:"last_operation"`
+	Relationships struct {
+		App             *v3Relationship `json:"app"`
+		ServiceInstance *v3Relationship `json:"service_instance"`
+	} `json:"relationships"`
+}
+
+func findV3AppBindingGUID(session *managers.Session, appGUID, serviceInstanceGUID string) (string, error) {
+	var bindings struct {
+		Resources []v3ServiceCredentialBinding `json:"resources"`
+	}
+	path := fmt.Sprintf("/v3/service_credential_bindings?type=app&app_guids=%s&service_instance_guids=%s", appGUID, serviceInstanceGUID)
+	_, err := v3Do(session, "GET", path, nil, &bindings)
+	if err != nil {
+		return "", err
+	}
+	if len(bindings.Resources) == 0 {
+		return "", nil
+	}
+	return bindings.Resources[0].GUID, nil
+}
+
+func resourceServiceBindingCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	session := meta.(*managers.Session)
+
+	appGUID := d.Get("app").(string)
+	serviceInstanceGUID := d.Get("service_instance").(string)
+	params := d.Get("params").(map[string]interface{})
+	paramJson := d.Get("params_json").(string)
+	if len(params) == 0 && paramJson != "" {
+		err := json.Unmarshal([]byte(paramJson), &params)
+		if err != nil {
+			return diag.FromErr(err)
+		}
+	}
+
+	payload := map[string]interface{}{
+		"type": "app",
+		"relationships": map[string]interface{}{
+			"app":              newV3Relationship(appGUID),
+			"service_instance": newV3Relationship(serviceInstanceGUID),
+		},
+	}
+	if name := d.Get("name").(string); name != "" {
+		payload["name"] = name
+	}
+	if len(params) > 0 {
+		payload["parameters"] = params
+	}
+
+	var binding v3ServiceCredentialBinding
+	jobPath, err := v3Do(session, "POST", "/v3/service_credential_bindings", payload, &binding)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	var diags diag.Diagnostics
+	// binding to a user provided service is synchronous, managed one goes through broker
+	if jobPath != "" {
+		job, err := v3PollJob(ctx, session, jobPath, d.Timeout(schema.TimeoutCreate))
+		binding.GUID = job.LinkGUID("service_credential_bindings")
+		if binding.GUID == "" {
+			binding.GUID, _ = findV3AppBindingGUID(session, appGUID, serviceInstanceGUID)
+		}
+		// binding exists even when broker failed to create it, id is kept to let terraform taint it
+		if binding.GUID != "" {
+			d.SetId(binding.GUID)
+		}
+		if err != nil {
+			return serviceBindingOperationDiags(session, binding.GUID, job, err)
+		}
+		diags = job.WarningsDiags()
+	}
+	if binding.GUID == "" {
+		return diag.Errorf("Service binding between app %s and service instance %s not found after creation", appGUID, serviceInstanceGUID)
+	}
+	d.SetId(binding.GUID)
+
+	err = serviceBindingAppAction(d, session, d.Timeout(schema.TimeoutCreate))
+	if err != nil {
+		return append(diags, diag.FromErr(err)...)
+	}
+	return append(diags, resourceServiceBindingRead(ctx, d, meta)...)
+}
+
+// serviceBindingOperationDiags - turn a failed operation on binding into diagnostics,
+// description of last operation given by broker is added as detail when available
+func serviceBindingOperationDiags(session *managers.Session, guid string, job v3Job, err error) diag.Diagnostics {
+	diags := job.WarningsDiags()
+	errDiag := diag.Diagnostic{
+		Severity: diag.Error,
+		Summary:  err.Error(),
+	}
+	if guid != "" {
+		var binding v3ServiceCredentialBinding
+		_, errGet := v3Do(session, "GET", fmt.Sprintf("/v3/service_credential_bindings/%s", guid), nil, &binding)
+		if errGet == nil && binding.LastOperation.Description != "" {
+			errDiag.Detail = fmt.Sprintf("Last operation %s is %s: %s",
+				binding.LastOperation.Type, binding.LastOperation.State, binding.LastOperation.Description)
+		}
+	}
+	return append(diags, errDiag)
+}
+
+// serviceBindingAppAction - restart or restage bound app as asked, stopped app is left as is
+func serviceBindingAppAction(d *schema.ResourceData, session *managers.Session, timeout time.Duration) error {
+	restart := d.Get("restart_app").(bool)
+	restage := d.Get("restage_app").(bool)
+	if !restart && !restage {
+		return nil
+	}
+	app, _, err := session.ClientV2.GetApplication(d.Get("app").(string))
+	if err != nil {
+		if IsErrNotFound(err) {
+			return nil
+		}
+		return err
+	}
+	if app.State == constant.ApplicationStopped {
+		return nil
+	}
+	appDeploy := appdeployers.AppDeploy{
+		App:          app,
+		StageTimeout: timeout,
+		StartTimeout: timeout,
+		BindTimeout:  timeout,
+	}
+	if restage {
+		// restage the way app is deployed to avoid downtime of zero downtime apps
+		strategy, err := appRecordedStrategy(session, app.GUID)
+		if err != nil {
+			return err
+		}
+		deployer := appInPlaceDeployer(session, strategy)
+		log.Printf("[INFO] Restaging app %s with strategy %s to take service binding changes", app.Name, deployer.Names()[0])
+		_, err = deployer.Restage(appDeploy)
+		return err
+	}
+	log.Printf("[INFO] Restarting app %s to take service binding changes", app.Name)
+	return session.RunBinder.Restart(appDeploy, timeout)
+}
+
+func resourceServiceBindingRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	session := meta.(*managers.Session)
+
+	var binding v3ServiceCredentialBinding
+	_, err := v3Do(session, "GET", fmt.Sprintf("/v3/service_credential_bindings/%s", d.Id()), nil, &binding)
+	if err != nil {
+		if IsErrNotFound(err) {
+			d.SetId("")
+			return nil
+		}
+		return diag.FromErr(err)
+	}
+	d.Set("name", binding.Name)
+	d.Set("app", binding.Relationships.App.GUID())
+	d.Set("service_instance", binding.Relationships.ServiceInstance.GUID())
+
+	var details struct {
+		Credentials map[string]interface{} `json:"credentials"`
+	}
+	_, err = v3Do(session, "GET", fmt.Sprintf("/v3/service_credential_bindings/%s/details", d.Id()), nil, &details)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	d.Set("credentials", normalizeMap(details.Credentials, make(map[string]interface{}), "", "_"))
+	return nil
+}
+
+func resourceServiceBindingUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	// only restart_app and restage_app can be updated, they are used on next binding change
+	return nil
+}
+
+func resourceServiceBindingDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	session := meta.(*managers.Session)
+
+	jobPath, err := v3Do(session, "DELETE", fmt.Sprintf("/v3/service_credential_bindings/%s", d.Id()), nil, nil)
+	if err != nil {
+		if IsErrNotFound(err) {
+			return nil
+		}
+		return diag.FromErr(err)
+	}
+	var diags diag.Diagnostics
+	if jobPath != "" {
+		job, err := v3PollJob(ctx, session, jobPath, d.Timeout(schema.TimeoutDelete))
+		if err != nil {
+			return serviceBindingOperationDiags(session, d.Id(), job, err)
+		}
+		diags = job.WarningsDiags()
+	}
+	err = serviceBindingAppAction(d, session, d.Timeout(schema.TimeoutDelete))
+	if err != nil {
+		return append(diags, diag.FromErr(err)...)
+	}
+	return diags
+}
@@ -0,0 +1,112 @@
+package cloudfoundry
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+const serviceBindingResource = `
+data "cloudfoundry_service" "test-service" {
+  name = "%s"
+}
+
+resource "cloudfoundry_service_instance" "test-service-instance-bound" {
+  name = "test-service-instance-bound"
+  space = "%s"
+  service_plan = "${data.cloudfoundry_service.test-service.service_plans["%s"]}"
+}
+
+resource "cloudfoundry_app" "dummy-app-bound" {
+  name = "dummy-app-bound"
+  buildpack = "binary_buildpack"
+  space = "%s"
+  memory = "64"
+  disk_quota = "512"
+  timeout = 1800
+  path = "%s"
+}
+
+resource "cloudfoundry_service_binding" "binding" {
+  app = cloudfoundry_app.dummy-app-bound.id
+  service_instance = cloudfoundry_service_instance.test-service-instance-bound.id
+  name = "my-binding"
+  restage_app = true
+}
+`
+
+func TestAccResServiceBinding_normal(t *testing.T) {
+
+	spaceID, _ := defaultTestSpace(t)
+	serviceName1, _, servicePlan := getTestServiceBrokers(t)
+
+	ref := "cloudfoundry_service_binding.binding"
+	resource.Test(t,
+		resource.TestCase{
+			PreCheck:          func() { testAccPreCheck(t) },
+			ProviderFactories: testAccProvidersFactories,
+			CheckDestroy:      testAccCheckServiceBindingDestroyed(ref),
+			Steps: []resource.TestStep{
+
+				resource.TestStep{
+					Config: fmt.Sprintf(serviceBindingResource,
+						serviceName1, spaceID, servicePlan, spaceID, appPath),
+					Check: resource.ComposeTestCheckFunc(
+						testAccCheckServiceBindingExists(ref),
+						resource.TestCheckResourceAttr(ref, "name", "my-binding"),
+						resource.TestCheckResourceAttrPair(ref, "app", "cloudfoundry_app.dummy-app-bound", "id"),
+						resource.TestCheckResourceAttrPair(ref, "service_instance",
+							"cloudfoundry_service_instance.test-service-instance-bound", "id"),
+					),
+				},
+
+				resource.TestStep{
+					ResourceName:            ref,
+					ImportState:             true,
+					ImportStateVerify:       true,
+					ImportStateVerifyIgnore: []string{"restage_app", "restart_app"},
+				},
+			},
+		})
+}
+
+func testAccCheckServiceBindingExists(resource string) resource.TestCheckFunc {
+
+	return func(s *terraform.State) error {
+
+		rs, ok := s.RootModule().Resources[resource]
+		if !ok {
+			return fmt.Errorf("service binding '%s' not found in terraform state", resource)
+		}
+
+		guid, err := findV3AppBindingGUID(testSession(), rs.Primary.Attributes["app"], rs.Primary.Attributes["service_instance"])
+		if err != nil {
+			return err
+		}
+		if guid != rs.Primary.ID {
+			return fmt.Errorf("expected service binding %s between app and service instance but found '%s'", rs.Primary.ID, guid)
+		}
+		return nil
+	}
+}
+
+func testAccCheckServiceBindingDestroyed(resource string) resource.TestCheckFunc {
+
+	return func(s *terraform.State) error {
+
+		rs, ok := s.RootModule().Resources[resource]
+		if !ok {
+			return nil
+		}
+		_, err := v3Do(testSession(), "GET", fmt.Sprintf("/v3/service_credential_bindings/%s", rs.Primary.ID), nil, nil)
+		if err == nil {
+			return fmt.Errorf("service binding %s still exists", rs.Primary.ID)
+		}
+		if !IsErrNotFound(err) {
+			return err
+		}
+		return nil
+	}
+}
@@ -4,15 +4,16 @@ import (
 	"context"
 	"crypto/sha1"
 	"encoding/base64"
+	"encoding/json"
 	"fmt"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"io/ioutil"
 	"log"
 	"net/http"
+	"net/url"
 	"strings"
+	"time"
 
-	"code.cloudfoundry.org/cli/api/cloudcontroller/ccv2"
-	"code.cloudfoundry.org/cli/api/cloudcontroller/ccv2/constant"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/terraform-providers/terraform-provider-cloudfoundry/cloudfoundry/managers"
 )
@@ -34,6 +35,12 @@ func resourceServiceBroker() *schema.Resource {
 			StateContext: ImportReadContext(resourceServiceBrokerRead),
 		},
 
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(15 * time.Minute),
+			Update: schema.DefaultTimeout(15 * time.Minute),
+			Delete: schema.DefaultTimeout(15 * time.Minute),
+		},
+
 		Schema: map[string]*schema.Schema{
 
 			"name": &schema.Schema{
@@ -86,7 +93,84 @@ func resourceServiceBroker() *schema.Resource {
 			labelsKey:      labelsSchema(),
 			annotationsKey: annotationsSchema(),
 		},
+
+		CustomizeDiff: serviceBrokerCatalogCustomizeDiff,
+	}
+}
+
+// serviceBrokerCatalogCustomizeDiff - validate catalog of broker before cloud controller is asked to register or update it,
+// broker is updated on any change and when catalog has changed
+func serviceBrokerCatalogCustomizeDiff(ctx context.Context, diff *schema.ResourceDiff, meta interface{}) error {
+	if diff.Id() != "" {
+		changed := false
+		for _, k := range []string{"name", "url", "username", "password", "catalog_change"} {
+			changed = changed || diff.HasChange(k)
+		}
+		if !changed {
+			return nil
+		}
+	}
+	for _, k := range []string{"url", "username", "password"} {
+		if !diff.NewValueKnown(k) {
+			return nil
+		}
+	}
+	session := meta.(*managers.Session)
+	brokerUrl := diff.Get("url").(string)
+	catalog, err := serviceBrokerFetchCatalog(session.HttpClient, brokerUrl, diff.Get("username").(string), diff.Get("password").(string))
+	if err != nil {
+		// not accessible catalog is handled on apply as it was before
+		log.Printf("[WARN] skipping validation of catalog of service broker %s: %s", brokerUrl, err.Error())
+		return nil
+	}
+	violations := validateBrokerCatalog(catalog)
+	if len(violations) > 0 {
+		return brokerCatalogError(brokerUrl, violations)
+	}
+	return nil
+}
+
+// v3ServiceBroker - service broker as given by cloud controller v3 api
+type v3ServiceBroker struct {
+	GUID          string `json:"guid"`
+	Name          string `json:"name"`
+	URL           string `json:"url"`
+	Relationships struct {
+		Space *v3Relationship `json:"space"`
+	} `json:"relationships"`
+}
+
+// serviceBrokerPayload - payload for creating or updating a broker, giving url and authentication makes cloud controller
+// fetch catalog from broker again
+func serviceBrokerPayload(d *schema.ResourceData) map[string]interface{} {
+	return map[string]interface{}{
+		"name": d.Get("name").(string),
+		"url":  d.Get("url").(string),
+		"authentication": map[string]interface{}{
+			"type": "basic",
+			"credentials": map[string]interface{}{
+				"username": d.Get("username").(string),
+				"password": d.Get("password").(string),
+			},
+		},
+	}
+}
+
+// serviceBrokerUpdatePayload - payload with changed fields of broker only, each update makes cloud controller
+// synchronize catalog in a job, it is empty when only metadata has changed.
+// Url is given again when catalog of broker has changed (or its hash is set) to synchronize it
+func serviceBrokerUpdatePayload(d *schema.ResourceData) map[string]interface{} {
+	payload := serviceBrokerPayload(d)
+	if !d.HasChange("name") {
+		delete(payload, "name")
+	}
+	if !d.HasChange("url") && !d.HasChange("catalog_change") && !d.HasChange("catalog_hash") {
+		delete(payload, "url")
+	}
+	if !d.HasChange("username") && !d.HasChange("password") {
+		delete(payload, "authentication")
 	}
+	return payload
 }
 
 func resourceServiceBrokerCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
@@ -98,27 +182,38 @@ func resourceServiceBrokerCreate(ctx context.Context, d *schema.ResourceData, me
 		return diag.FromErr(err)
 	}
 
-	sb, _, err := session.ClientV2.CreateServiceBroker(
-		d.Get("name").(string),
-		d.Get("username").(string),
-		d.Get("password").(string),
-		d.Get("url").(string),
-		d.Get("space").(string),
-	)
+	payload := serviceBrokerPayload(d)
+	if space := d.Get("space").(string); space != "" {
+		payload["relationships"] = map[string]interface{}{
+			"space": newV3Relationship(space),
+		}
+	}
+	jobPath, err := v3Do(session, "POST", "/v3/service_brokers", payload, nil)
 	if err != nil {
 		return diag.FromErr(err)
 	}
-	if err = readServiceDetail(sb.GUID, session, d); err != nil {
-		return diag.FromErr(err)
+	job, err := v3PollJob(ctx, session, jobPath, d.Timeout(schema.TimeoutCreate))
+	diags := job.WarningsDiags()
+	// broker is registered even when catalog synchronization failed, id is kept to let terraform taint it
+	if guid := job.LinkGUID("service_brokers"); guid != "" {
+		d.SetId(guid)
+	}
+	if err != nil {
+		return append(diags, diag.Errorf("Error while registering service broker %s: %s", d.Get("name").(string), err)...)
+	}
+	if d.Id() == "" {
+		return append(diags, diag.Errorf("Service broker %s not found after registration", d.Get("name").(string))...)
+	}
+	if err = readServiceDetail(d.Id(), session, d); err != nil {
+		return append(diags, diag.FromErr(err)...)
 	}
-	d.SetId(sb.GUID)
 
 	err = metadataCreate(serviceBrokerMetadata, d, meta)
 	if err != nil {
-		return diag.FromErr(err)
+		return append(diags, diag.FromErr(err)...)
 	}
 
-	return nil
+	return diags
 }
 
 func resourceServiceBrokerRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
@@ -130,7 +225,8 @@ func resourceServiceBrokerRead(ctx context.Context, d *schema.ResourceData, meta
 		return diag.FromErr(err)
 	}
 
-	sb, _, err := session.ClientV2.GetServiceBroker(d.Id())
+	var sb v3ServiceBroker
+	_, err = v3Do(session, "GET", fmt.Sprintf("/v3/service_brokers/%s", d.Id()), nil, &sb)
 	if err != nil {
 		if IsErrNotFound(err) {
 			d.SetId("")
@@ -144,9 +240,15 @@ func resourceServiceBrokerRead(ctx context.Context, d *schema.ResourceData, meta
 	}
 
 	d.Set("name", sb.Name)
-	d.Set("url", sb.BrokerURL)
-	d.Set("username", sb.AuthUsername)
-	d.Set("space", sb.SpaceGUID)
+	d.Set("url", sb.URL)
+	d.Set("space", sb.Relationships.Space.GUID())
+
+	// v3 api never gives back credentials, username is still given by v2 api
+	sbV2, _, err := session.ClientV2.GetServiceBroker(d.Id())
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	d.Set("username", sbV2.AuthUsername)
 
 	err = metadataRead(serviceBrokerMetadata, d, meta, false)
 	if err != nil {
@@ -158,84 +260,136 @@ func resourceServiceBrokerRead(ctx context.Context, d *schema.ResourceData, meta
 func resourceServiceBrokerUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	session := meta.(*managers.Session)
 
-	// do as first to not try add broker if catalog not accessible
-	err := serviceBrokerUpdateCatalogSignature(d, meta)
-	if err != nil {
-		return diag.FromErr(err)
-	}
+	// payload is made before catalog_change is set again by catalog signature
+	var diags diag.Diagnostics
+	payload := serviceBrokerUpdatePayload(d)
+	if len(payload) > 0 {
+		// do as first to not try add broker if catalog not accessible
+		err := serviceBrokerUpdateCatalogSignature(d, meta)
+		if err != nil {
+			return diag.FromErr(err)
+		}
 
-	_, _, err = session.ClientV2.UpdateServiceBroker(ccv2.ServiceBroker{
-		GUID:         d.Id(),
-		AuthUsername: d.Get("username").(string),
-		AuthPassword: d.Get("password").(string),
-		BrokerURL:    d.Get("url").(string),
-		SpaceGUID:    d.Get("space").(string),
-		Name:         d.Get("name").(string),
-	})
-	if err != nil {
-		return diag.FromErr(err)
-	}
+		jobPath, err := v3Do(session, "PATCH", fmt.Sprintf("/v3/service_brokers/%s", d.Id()), payload, nil)
+		if err != nil {
+			return diag.FromErr(err)
+		}
+		if jobPath != "" {
+			job, err := v3PollJob(ctx, session, jobPath, d.Timeout(schema.TimeoutUpdate))
+			diags = job.WarningsDiags()
+			if err != nil {
+				return append(diags, diag.Errorf("Error while updating service broker %s: %s", d.Get("name").(string), err)...)
+			}
+		}
 
-	if err = readServiceDetail(d.Id(), session, d); err != nil {
-		return diag.FromErr(err)
+		if err = readServiceDetail(d.Id(), session, d); err != nil {
+			return append(diags, diag.FromErr(err)...)
+		}
 	}
 
-	err = metadataUpdate(serviceBrokerMetadata, d, meta)
+	err := metadataUpdate(serviceBrokerMetadata, d, meta)
 	if err != nil {
-		return diag.FromErr(err)
+		return append(diags, diag.FromErr(err)...)
 	}
-	return nil
+	return diags
 }
 
 func resourceServiceBrokerDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	session := meta.(*managers.Session)
-	if !session.PurgeWhenDelete {
-		_, err := session.ClientV2.DeleteServiceBroker(d.Id())
-		return diag.FromErr(err)
+	if session.PurgeWhenDelete {
+		err := purgeServiceBrokerInstances(session, d.Id())
+		if err != nil {
+			return diag.FromErr(err)
+		}
 	}
 
-	svcs, _, err := session.ClientV2.GetServices(ccv2.FilterEqual(constant.ServiceBrokerGUIDFilter, d.Id()))
+	jobPath, err := v3Do(session, "DELETE", fmt.Sprintf("/v3/service_brokers/%s", d.Id()), nil, nil)
 	if err != nil {
+		if IsErrNotFound(err) {
+			return nil
+		}
 		return diag.FromErr(err)
 	}
-	for _, svc := range svcs {
-		sis, _, err := session.ClientV2.GetServiceInstances(ccv2.FilterEqual(constant.ServiceGUIDFilter, svc.GUID))
+	if jobPath == "" {
+		return nil
+	}
+	job, err := v3PollJob(ctx, session, jobPath, d.Timeout(schema.TimeoutDelete))
+	diags := job.WarningsDiags()
+	if err != nil {
+		return append(diags, diag.FromErr(err)...)
+	}
+	return diags
+}
+
+// purgeServiceBrokerInstances - purge service instances of all plans of broker without calling it
+func purgeServiceBrokerInstances(session *managers.Session, brokerGUID string) error {
+	plans, err := getV3ServicePlans(session, url.Values{"service_broker_guids": []string{brokerGUID}}, marketplaceAll)
+	if err != nil {
+		return err
+	}
+	if len(plans) == 0 {
+		return nil
+	}
+	planGUIDs := make([]string, len(plans))
+	for i, p := range plans {
+		planGUIDs[i] = p.GUID
+	}
+	guids := make([]string, 0)
+	err = v3GetAll(session, "/v3/service_instances?service_plan_guids="+strings.Join(planGUIDs, ","), func(resources json.RawMessage) error {
+		var sis []v3ServiceInstance
+		err := json.Unmarshal(resources, &sis)
 		if err != nil {
-			return diag.FromErr(err)
+			return err
 		}
 		for _, si := range sis {
-			_, _, err := session.ClientV2.DeleteServiceInstance(si.GUID, true, true)
-			if err != nil {
-				return diag.FromErr(err)
-			}
+			guids = append(guids, si.GUID)
 		}
-	}
-	_, err = session.ClientV2.DeleteServiceBroker(d.Id())
-	return diag.FromErr(err)
-}
-
-func readServiceDetail(id string, session *managers.Session, d *schema.ResourceData) error {
-	services, _, err := session.ClientV2.GetServices(ccv2.FilterEqual(constant.ServiceBrokerGUIDFilter, id))
+		return nil
+	})
 	if err != nil {
 		return err
 	}
+	for _, guid := range guids {
+		_, err := v3Do(session, "DELETE", fmt.Sprintf("/v3/service_instances/%s?purge=true", guid), nil, nil)
+		if err != nil && !IsErrNotFound(err) {
+			return err
+		}
+	}
+	return nil
+}
 
-	servicePlansTf := make(map[string]interface{})
+func readServiceDetail(id string, session *managers.Session, d *schema.ResourceData) error {
+	query := url.Values{"service_broker_guids": []string{id}}
+	offeringNames := make(map[string]string)
 	servicesTf := make(map[string]interface{})
-	for _, s := range services {
-		servicesTf[s.Label] = s.GUID
-		servicePlans, _, err := session.ClientV2.GetServicePlans(ccv2.FilterEqual(constant.ServiceGUIDFilter, s.GUID))
+	err := v3GetAll(session, "/v3/service_offerings?"+query.Encode(), func(resources json.RawMessage) error {
+		var offerings []v3ServiceOffering
+		err := json.Unmarshal(resources, &offerings)
 		if err != nil {
 			return err
 		}
-		for _, sp := range servicePlans {
-			servicePlansTf[s.Label+"/"+sp.Name] = sp.GUID
+		for _, o := range offerings {
+			offeringNames[o.GUID] = o.Name
+			servicesTf[o.Name] = o.GUID
 		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	plans, err := getV3ServicePlans(session, query, marketplaceAll)
+	if err != nil {
+		return err
+	}
+	servicePlansTf := make(map[string]interface{})
+	for _, sp := range plans {
+		servicePlansTf[offeringNames[sp.Relationships.ServiceOffering.GUID()]+"/"+sp.Name] = sp.GUID
 	}
 	d.Set("service_plans", servicePlansTf)
 	d.Set("services", servicesTf)
 
-	return err
+	return nil
 }
 
 func serviceBrokerUpdateCatalogSignature(d *schema.ResourceData, meta interface{}) error {
@@ -272,36 +426,45 @@ func serviceBrokerUpdateCatalogSignature(d *schema.ResourceData, meta interface{
 }
 
 func serviceBrokerCatalogSignature(d *schema.ResourceData, meta interface{}) (string, error) {
-	client := meta.(*managers.Session).HttpClient
-	catalogUrl := d.Get("url").(string)
-	if strings.HasSuffix(catalogUrl, "/") {
-		catalogUrl = strings.TrimSuffix(catalogUrl, "/")
+	bodyBytes, err := serviceBrokerFetchCatalog(
+		meta.(*managers.Session).HttpClient,
+		d.Get("url").(string),
+		d.Get("username").(string),
+		d.Get("password").(string),
+	)
+	if err != nil {
+		return "", err
 	}
-	catalogUrl += catalogEndpoint
-	req, err := http.NewRequest("GET", catalogUrl, nil)
+
+	h := sha1.New()
+	_, err = h.Write(bodyBytes)
 	if err != nil {
 		return "", err
 	}
+	return base64.URLEncoding.EncodeToString(h.Sum(nil)), nil
+}
+
+// serviceBrokerFetchCatalog - get catalog directly from broker as cloud controller would
+func serviceBrokerFetchCatalog(client *http.Client, brokerUrl, username, password string) ([]byte, error) {
+	catalogUrl := strings.TrimSuffix(brokerUrl, "/") + catalogEndpoint
+	req, err := http.NewRequest("GET", catalogUrl, nil)
+	if err != nil {
+		return nil, err
+	}
 	req.Header.Add("X-Broker-API-Version", "2.11")
-	req.SetBasicAuth(d.Get("username").(string), d.Get("password").(string))
+	req.SetBasicAuth(username, password)
 	resp, err := client.Do(req)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 	defer resp.Body.Close()
 
 	bodyBytes, err := ioutil.ReadAll(resp.Body)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 	if resp.StatusCode != 200 {
-		return "", fmt.Errorf("Status code: %s, Body: %s ", resp.Status, string(bodyBytes))
-	}
-
-	h := sha1.New()
-	_, err = h.Write(bodyBytes)
-	if err != nil {
-		return "", err
+		return nil, fmt.Errorf("Status code: %s, Body: %s ", resp.Status, string(bodyBytes))
 	}
-	return base64.URLEncoding.EncodeToString(h.Sum(nil)), nil
+	return bodyBytes, nil
 }
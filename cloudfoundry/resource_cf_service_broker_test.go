@@ -2,8 +2,12 @@ package cloudfoundry
 
 import (
 	"code.cloudfoundry.org/cli/api/cloudcontroller/ccv2"
+	"context"
 	"fmt"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/terraform-providers/terraform-provider-cloudfoundry/cloudfoundry/managers"
+	"net/http"
+	"net/http/httptest"
 	"regexp"
 	"testing"
 
@@ -61,6 +65,17 @@ resource "cloudfoundry_service_broker" "test" {
 }
 `
 
+const sbResourceSpaceScoped = `
+
+resource "cloudfoundry_service_broker" "test" {
+	name = "test-space-scoped"
+	url = "%s"
+	username = "%s"
+	password = "%s"
+	space = "%s"
+}
+`
+
 func TestAccResServiceBroker_normal(t *testing.T) {
 
 	serviceBrokerURL, serviceBrokerUser, serviceBrokerPassword, serviceBrokerPlanPath := getTestBrokerCredentials(t)
@@ -128,6 +143,75 @@ func TestAccResServiceBroker_normal(t *testing.T) {
 		})
 }
 
+func TestAccResServiceBroker_spaceScoped(t *testing.T) {
+
+	serviceBrokerURL, serviceBrokerUser, serviceBrokerPassword, serviceBrokerPlanPath := getTestBrokerCredentials(t)
+	spaceID, _ := defaultTestSpace(t)
+
+	deleteServiceBroker("test-space-scoped")
+
+	ref := "cloudfoundry_service_broker.test"
+	resource.Test(t,
+		resource.TestCase{
+			PreCheck:          func() { testAccPreCheck(t) },
+			ProviderFactories: testAccProvidersFactories,
+			CheckDestroy:      testAccCheckServiceBrokerDestroyed("test-space-scoped"),
+			Steps: []resource.TestStep{
+
+				resource.TestStep{
+					Config: fmt.Sprintf(sbResourceSpaceScoped,
+						serviceBrokerURL, serviceBrokerUser, serviceBrokerPassword, spaceID),
+					Check: resource.ComposeTestCheckFunc(
+						testAccCheckServiceBrokerExists(ref, nil),
+						resource.TestCheckResourceAttr(
+							ref, "name", "test-space-scoped"),
+						resource.TestCheckResourceAttr(
+							ref, "space", spaceID),
+						resource.TestCheckResourceAttrSet(
+							ref, "service_plans."+serviceBrokerPlanPath),
+					),
+				},
+
+				resource.TestStep{
+					ResourceName:            ref,
+					ImportState:             true,
+					ImportStateVerify:       true,
+					ImportStateVerifyIgnore: []string{"password", "catalog_change", "catalog_hash", "fail_when_catalog_not_accessible"},
+				},
+			},
+		})
+}
+
+func TestAccResServiceBroker_invalidCatalog(t *testing.T) {
+
+	broker := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(brokerCatalogInvalid))
+	}))
+	defer broker.Close()
+
+	resource.ParallelTest(t,
+		resource.TestCase{
+			PreCheck:          func() { testAccPreCheck(t) },
+			ProviderFactories: testAccProvidersFactories,
+			CheckDestroy:      testAccCheckServiceBrokerDestroyed("test-invalid-catalog"),
+			Steps: []resource.TestStep{
+				resource.TestStep{
+					Config: fmt.Sprintf(`
+resource "cloudfoundry_service_broker" "test" {
+	name = "test-invalid-catalog"
+	url = "%s"
+	username = "admin"
+	password = "admin"
+}
+`, broker.URL),
+					PlanOnly:    true,
+					ExpectError: regexp.MustCompile(`services\[1\]\.id: id "svc-1" is already used by services\[0\]`),
+				},
+			},
+		})
+}
+
 func TestAccResServiceBroker_fail(t *testing.T) {
 
 	serviceBrokerURL, serviceBrokerUser, serviceBrokerPassword, _ := getTestBrokerCredentials(t)
@@ -228,3 +312,60 @@ func testAccCheckServiceBrokerDestroyed(name string) resource.TestCheckFunc {
 		return nil
 	}
 }
+
+func TestServiceBrokerUpdatePayload(t *testing.T) {
+	// catalog is not accessible, it is not validated on diff
+	broker := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer broker.Close()
+	session := &managers.Session{HttpClient: broker.Client()}
+
+	r := resourceServiceBroker()
+	base := map[string]interface{}{
+		"name":     "broker",
+		"url":      broker.URL,
+		"username": "admin",
+		"password": "secret",
+	}
+	d := schema.TestResourceDataRaw(t, r.Schema, base)
+	d.SetId("broker-guid")
+	d.Set("catalog_hash", "hash")
+	state := d.State()
+
+	for _, c := range []struct {
+		name     string
+		changes  map[string]interface{}
+		expected string
+	}{
+		{"labels", map[string]interface{}{labelsKey: map[string]interface{}{"env": "prod"}}, "[]"},
+		{"name", map[string]interface{}{"name": "broker-renamed"}, "[name]"},
+		{"password", map[string]interface{}{"password": "new-secret"}, "[authentication]"},
+		{"catalog", map[string]interface{}{"catalog_hash": "1"}, "[url]"},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			config := make(map[string]interface{})
+			for k, v := range base {
+				config[k] = v
+			}
+			for k, v := range c.changes {
+				config[k] = v
+			}
+			diff, err := r.Diff(context.Background(), state, terraform.NewResourceConfigRaw(config), session)
+			if err != nil {
+				t.Fatalf("diff failed: %s", err)
+			}
+			d, err := schema.InternalMap(r.Schema).Data(state, diff)
+			if err != nil {
+				t.Fatal(err)
+			}
+			keys := make([]string, 0)
+			for k := range serviceBrokerUpdatePayload(d) {
+				keys = append(keys, k)
+			}
+			if fmt.Sprint(keys) != c.expected {
+				t.Fatalf("expected update of %s but found %v", c.expected, keys)
+			}
+		})
+	}
+}
@@ -5,16 +5,17 @@ import (
 	"encoding/json"
 	"fmt"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"log"
+	"net/url"
+	"strings"
 	"time"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/customdiff"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/structure"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 
-	"code.cloudfoundry.org/cli/api/cloudcontroller/ccv2"
-	"code.cloudfoundry.org/cli/api/cloudcontroller/ccv2/constant"
 	"github.com/terraform-providers/terraform-provider-cloudfoundry/cloudfoundry/managers"
 
-	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 )
 
@@ -56,10 +57,11 @@ func resourceServiceInstance() *schema.Resource {
 				ForceNew: true,
 			},
 			"json_params": &schema.Schema{
-				Type:         schema.TypeString,
-				Optional:     true,
-				Default:      "",
-				ValidateFunc: validation.StringIsJSON,
+				Type:             schema.TypeString,
+				Optional:         true,
+				Default:          "",
+				ValidateFunc:     validation.StringIsJSON,
+				DiffSuppressFunc: structure.SuppressJsonDiff,
 			},
 			"replace_on_service_plan_change": {
 				Type:     schema.TypeBool,
@@ -81,6 +83,20 @@ func resourceServiceInstance() *schema.Resource {
 				Optional: true,
 				Default:  false,
 			},
+			"auto_upgrade": &schema.Schema{
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "Upgrade instance to maintenance info version of its plan when an upgrade is available",
+			},
+			"maintenance_info_version": &schema.Schema{
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"upgrade_available": &schema.Schema{
+				Type:     schema.TypeBool,
+				Computed: true,
+			},
 		},
 		CustomizeDiff: customdiff.All(
 			customdiff.ForceNewIf(
@@ -91,17 +107,63 @@ func resourceServiceInstance() *schema.Resource {
 					return false
 				}),
 			customdiff.ForceNewIf(
-				"params", func(_ context.Context, d *schema.ResourceDiff, meta interface{}) bool {
+				"json_params", func(_ context.Context, d *schema.ResourceDiff, meta interface{}) bool {
 					if ok := d.Get("replace_on_params_change").(bool); ok {
 						return true
 					}
 					return false
 				},
 			),
+			func(_ context.Context, d *schema.ResourceDiff, meta interface{}) error {
+				if d.Id() == "" {
+					return nil
+				}
+				if d.HasChange("service_plan") {
+					if err := d.SetNewComputed("upgrade_available"); err != nil {
+						return err
+					}
+					return d.SetNewComputed("maintenance_info_version")
+				}
+				if d.Get("auto_upgrade").(bool) && d.Get("upgrade_available").(bool) {
+					if err := d.SetNew("upgrade_available", false); err != nil {
+						return err
+					}
+					return d.SetNewComputed("maintenance_info_version")
+				}
+				return nil
+			},
+			serviceInstanceParamsCustomizeDiff,
 		),
 	}
 }
 
+// v3ServiceInstance - service instance as given by cloud controller v3 api
+type v3ServiceInstance struct {
+	GUID          string   `json:"guid"`
+	Name          string   `json:"name"`
+	Type          string   `json:"type"`
+	Tags          []string `json:"tags"`
+	LastOperation struct {
+		Type        string `json:"type"`
+		State       string `json:"state"`
+		Description string `json:"description"`
+	} `json:"last_operation"`
+	MaintenanceInfo struct {
+		Version string `json:"version"`
+	} `json:"maintenance_info"`
+	UpgradeAvailable bool `json:"upgrade_available"`
+	Relationships    struct {
+		Space       *v3Relationship `json:"space"`
+		ServicePlan *v3Relationship `json:"service_plan"`
+	} `json:"relationships"`
+}
+
+func getV3ServiceInstance(session *managers.Session, guid string) (v3ServiceInstance, error) {
+	var si v3ServiceInstance
+	_, err := v3Do(session, "GET", fmt.Sprintf("/v3/service_instances/%s", guid), nil, &si)
+	return si, err
+}
+
 func resourceServiceInstanceCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	session := meta.(*managers.Session)
 
@@ -114,41 +176,89 @@ func resourceServiceInstanceCreate(ctx context.Context, d *schema.ResourceData,
 		tags = append(tags, v.(string))
 	}
 
-	params := make(map[string]interface{})
+	payload := map[string]interface{}{
+		"type": "managed",
+		"name": name,
+		"tags": tags,
+		"relationships": map[string]interface{}{
+			"space":        newV3Relationship(space),
+			"service_plan": newV3Relationship(servicePlan),
+		},
+	}
 	if len(jsonParameters) > 0 {
+		params := make(map[string]interface{})
 		err := json.Unmarshal([]byte(jsonParameters), &params)
 		if err != nil {
 			return diag.FromErr(err)
 		}
+		payload["parameters"] = params
 	}
-	si, _, err := session.ClientV2.CreateServiceInstance(space, servicePlan, name, params, tags)
+	jobPath, err := v3Do(session, "POST", "/v3/service_instances", payload, nil)
 	if err != nil {
 		return diag.FromErr(err)
 	}
-	stateConf := &resource.StateChangeConf{
-		Pending:        resourceServiceInstancePendingStates,
-		Target:         resourceServiceInstanceSuccessStates,
-		Refresh:        resourceServiceInstanceStateFunc(si.GUID, "create", meta),
-		Timeout:        d.Timeout(schema.TimeoutCreate),
-		PollInterval:   30 * time.Second,
-		Delay:          5 * time.Second,
-		NotFoundChecks: 6, // if the CF object for the instance isn't at least present after 3 minutes, it's probably not coming
-	}
+	job, err := v3PollJob(ctx, session, jobPath, d.Timeout(schema.TimeoutCreate))
 
-	// Wait, catching any errors
-	if _, err = stateConf.WaitForStateContext(ctx); err != nil {
+	// instance exists even when broker failed to provision it, id is kept to let terraform taint it
+	guid := job.LinkGUID("service_instances")
+	if guid == "" {
+		guid, _ = findV3ServiceInstanceGUID(session, name, space)
+	}
+	if guid != "" {
+		d.SetId(guid)
+	}
+	if err != nil {
+		return serviceInstanceOperationDiags(session, guid, job, err)
+	}
+	if guid == "" {
+		return diag.Errorf("Service instance %s not found after creation", name)
+	}
+	si, err := getV3ServiceInstance(session, guid)
+	if err != nil {
 		return diag.FromErr(err)
 	}
+	d.Set("maintenance_info_version", si.MaintenanceInfo.Version)
+	d.Set("upgrade_available", si.UpgradeAvailable)
+	return job.WarningsDiags()
+}
 
-	d.SetId(si.GUID)
+func findV3ServiceInstanceGUID(session *managers.Session, name string, space string) (string, error) {
+	var sis struct {
+		Resources []v3ServiceInstance `json:"resources"`
+	}
+	path := fmt.Sprintf("/v3/service_instances?names=%s&space_guids=%s", url.QueryEscape(name), space)
+	_, err := v3Do(session, "GET", path, nil, &sis)
+	if err != nil {
+		return "", err
+	}
+	if len(sis.Resources) == 0 {
+		return "", nil
+	}
+	return sis.Resources[0].GUID, nil
+}
 
-	return nil
+// serviceInstanceOperationDiags - turn a failed operation on service instance into diagnostics,
+// description of last operation given by broker is added as detail when available
+func serviceInstanceOperationDiags(session *managers.Session, guid string, job v3Job, err error) diag.Diagnostics {
+	diags := job.WarningsDiags()
+	errDiag := diag.Diagnostic{
+		Severity: diag.Error,
+		Summary:  err.Error(),
+	}
+	if guid != "" {
+		si, errGet := getV3ServiceInstance(session, guid)
+		if errGet == nil && si.LastOperation.Description != "" {
+			errDiag.Detail = fmt.Sprintf("Last operation %s is %s: %s",
+				si.LastOperation.Type, si.LastOperation.State, si.LastOperation.Description)
+		}
+	}
+	return append(diags, errDiag)
 }
 
 func resourceServiceInstanceRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	session := meta.(*managers.Session)
 
-	serviceInstance, _, err := session.ClientV2.GetServiceInstance(d.Id())
+	serviceInstance, err := getV3ServiceInstance(session, d.Id())
 	if err != nil {
 		if IsErrNotFound(err) {
 			d.SetId("")
@@ -158,10 +268,39 @@ func resourceServiceInstanceRead(ctx context.Context, d *schema.ResourceData, me
 	}
 
 	d.Set("name", serviceInstance.Name)
-	d.Set("service_plan", serviceInstance.ServicePlanGUID)
-	d.Set("space", serviceInstance.SpaceGUID)
+	d.Set("service_plan", serviceInstance.Relationships.ServicePlan.GUID())
+	d.Set("space", serviceInstance.Relationships.Space.GUID())
+	d.Set("maintenance_info_version", serviceInstance.MaintenanceInfo.Version)
+	d.Set("upgrade_available", serviceInstance.UpgradeAvailable)
+
+	var diags diag.Diagnostics
+	// params are only read back when they are managed, broker may give default params of instance created without any
+	retrievable := false
+	if d.Get("json_params").(string) != "" || IsImportState(d) {
+		retrievable, err = serviceInstanceParamsRetrievable(session, serviceInstance.Relationships.ServicePlan.GUID())
+		if err != nil {
+			return diag.FromErr(err)
+		}
+	}
+	if retrievable {
+		params, err := getServiceInstanceParams(session, d.Id())
+		if err != nil {
+			// broker may be down or busy with an operation on instance, params in state are kept
+			diags = append(diags, diag.Diagnostic{
+				Severity: diag.Warning,
+				Summary:  fmt.Sprintf("Unable to read params of service instance %s from its broker, params in state are kept", serviceInstance.Name),
+				Detail:   err.Error(),
+			})
+		} else {
+			// no params from broker is the same as no params set
+			if params == "{}" && d.Get("json_params").(string) == "" {
+				params = ""
+			}
+			d.Set("json_params", params)
+		}
+	}
 
-	if serviceInstance.Tags != nil {
+	if len(serviceInstance.Tags) > 0 {
 		tags := make([]interface{}, len(serviceInstance.Tags))
 		for i, v := range serviceInstance.Tags {
 			tags[i] = v
@@ -171,7 +310,7 @@ func resourceServiceInstanceRead(ctx context.Context, d *schema.ResourceData, me
 		d.Set("tags", nil)
 	}
 
-	return nil
+	return diags
 }
 
 func resourceServiceInstanceUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
@@ -181,144 +320,215 @@ func resourceServiceInstanceUpdate(ctx context.Context, d *schema.ResourceData,
 	}
 
 	// Enable partial state mode
-	// We need to explicitly set state updates ourselves or
-	// tell terraform when a state change is applied and thus okay to persist
-	// In particular this is necessary for params since we cannot query CF for
-	// the current value of this field
+	// State is left as before the update when it fails, params can't always be read back from broker
+	// and a failed change must be shown again on next plan to be retried
 	d.Partial(true)
 
-	var (
-		id, name string
-		tags     []string
-		params   map[string]interface{}
-	)
-
-	id = d.Id()
-	name = d.Get("name").(string)
-	servicePlan := d.Get("service_plan").(string)
-	jsonParameters := d.Get("json_params").(string)
+	id := d.Id()
+	payload := make(map[string]interface{})
+	if d.HasChange("name") {
+		payload["name"] = d.Get("name").(string)
+	}
+	if d.HasChange("tags") {
+		tags := make([]string, 0)
+		for _, v := range d.Get("tags").([]interface{}) {
+			tags = append(tags, v.(string))
+		}
+		payload["tags"] = tags
+	}
+	if d.HasChange("service_plan") {
+		payload["relationships"] = map[string]interface{}{
+			"service_plan": newV3Relationship(d.Get("service_plan").(string)),
+		}
+	}
+	if d.HasChange("json_params") {
+		// params removed from config are cleared on broker
+		params := make(map[string]interface{})
+		if jsonParameters := d.Get("json_params").(string); len(jsonParameters) > 0 {
+			err := json.Unmarshal([]byte(jsonParameters), &params)
+			if err != nil {
+				return diag.FromErr(err)
+			}
+		}
+		payload["parameters"] = params
+	}
 
-	if len(jsonParameters) > 0 {
-		err := json.Unmarshal([]byte(jsonParameters), &params)
+	var diags diag.Diagnostics
+	if len(payload) > 0 {
+		jobPath, err := v3Do(session, "PATCH", fmt.Sprintf("/v3/service_instances/%s", id), payload, nil)
 		if err != nil {
 			return diag.FromErr(err)
 		}
+		// only name and tags changes are done synchronously, other changes involve broker
+		if jobPath != "" {
+			job, err := v3PollJob(ctx, session, jobPath, d.Timeout(schema.TimeoutUpdate))
+			if err != nil {
+				return serviceInstanceOperationDiags(session, id, job, err)
+			}
+			diags = job.WarningsDiags()
+		}
 	}
+	// We succeeded, disable partial mode
+	d.Partial(false)
 
-	for _, v := range d.Get("tags").([]interface{}) {
-		tags = append(tags, v.(string))
-	}
-
-	_, _, err := session.ClientV2.UpdateServiceInstance(ccv2.ServiceInstance{
-		GUID:            id,
-		Name:            name,
-		ServicePlanGUID: servicePlan,
-		Parameters:      params,
-		Tags:            tags,
-	})
+	si, err := getV3ServiceInstance(session, id)
 	if err != nil {
-		return diag.FromErr(err)
+		return append(diags, diag.FromErr(err)...)
+	}
+	if d.Get("auto_upgrade").(bool) && si.UpgradeAvailable {
+		upgradeDiags := serviceInstanceUpgrade(ctx, d, session, si)
+		diags = append(diags, upgradeDiags...)
+		if upgradeDiags.HasError() {
+			return diags
+		}
+		si, err = getV3ServiceInstance(session, id)
+		if err != nil {
+			return append(diags, diag.FromErr(err)...)
+		}
 	}
+	d.Set("maintenance_info_version", si.MaintenanceInfo.Version)
+	d.Set("upgrade_available", si.UpgradeAvailable)
+	return diags
+}
 
-	stateConf := &resource.StateChangeConf{
-		Pending:        resourceServiceInstancePendingStates,
-		Target:         resourceServiceInstanceSuccessStates,
-		Refresh:        resourceServiceInstanceStateFunc(id, "update", meta),
-		Timeout:        d.Timeout(schema.TimeoutUpdate),
-		PollInterval:   30 * time.Second,
-		Delay:          5 * time.Second,
-		NotFoundChecks: 3, // if we don't find the service instance in CF during an update, something is definitely wrong
+// serviceInstanceUpgrade - upgrade service instance to maintenance info version of its plan
+func serviceInstanceUpgrade(ctx context.Context, d *schema.ResourceData, session *managers.Session, si v3ServiceInstance) diag.Diagnostics {
+	var plan struct {
+		MaintenanceInfo struct {
+			Version string `json:"version"`
+		} `json:"maintenance_info"`
 	}
-	// Wait, catching any errors
-	if _, err = stateConf.WaitForStateContext(ctx); err != nil {
+	_, err := v3Do(session, "GET", fmt.Sprintf("/v3/service_plans/%s", si.Relationships.ServicePlan.GUID()), nil, &plan)
+	if err != nil {
 		return diag.FromErr(err)
 	}
-
-	// We succeeded, disable partial mode
-	d.Partial(false)
-	return nil
-}
-
-func resourceServiceInstanceDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
-	session := meta.(*managers.Session)
-	id := d.Id()
-
-	recursiveDelete := d.Get("recursive_delete").(bool)
-	async, _, err := session.ClientV2.DeleteServiceInstance(id, recursiveDelete, session.PurgeWhenDelete)
+	log.Printf("[INFO] Upgrading service instance %s from version %s to %s", si.GUID, si.MaintenanceInfo.Version, plan.MaintenanceInfo.Version)
+	// maintenance info can't be changed along with other fields, upgrade is always done on its own
+	jobPath, err := v3Do(session, "PATCH", fmt.Sprintf("/v3/service_instances/%s", si.GUID), map[string]interface{}{
+		"maintenance_info": map[string]interface{}{
+			"version": plan.MaintenanceInfo.Version,
+		},
+	}, nil)
 	if err != nil {
 		return diag.FromErr(err)
 	}
-	if !async {
+	if jobPath == "" {
 		return nil
 	}
-	stateConf := &resource.StateChangeConf{
-		Pending:      resourceServiceInstancePendingStates,
-		Target:       []string{},
-		Refresh:      resourceServiceInstanceStateFunc(id, "delete", meta),
-		Timeout:      d.Timeout(schema.TimeoutDelete),
-		PollInterval: 30 * time.Second,
-		Delay:        5 * time.Second,
-	}
-	// Wait, catching any errors
-	if _, err = stateConf.WaitForStateContext(ctx); err != nil {
-		return diag.FromErr(err)
+	job, err := v3PollJob(ctx, session, jobPath, d.Timeout(schema.TimeoutUpdate))
+	if err != nil {
+		return serviceInstanceOperationDiags(session, si.GUID, job, err)
 	}
-
-	return nil
+	return job.WarningsDiags()
 }
 
-func resourceServiceInstanceImport(ctx context.Context, d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+// serviceInstanceParamsCustomizeDiff - validate json_params against schema published by broker for the plan,
+// create schema is used for a new instance and update schema otherwise
+func serviceInstanceParamsCustomizeDiff(_ context.Context, d *schema.ResourceDiff, meta interface{}) error {
+	if !d.NewValueKnown("json_params") || !d.NewValueKnown("service_plan") {
+		return nil
+	}
+	if d.Id() != "" && !d.HasChange("json_params") && !d.HasChange("service_plan") {
+		return nil
+	}
 	session := meta.(*managers.Session)
-
-	serviceinstance, _, err := session.ClientV2.GetServiceInstance(d.Id())
-
+	schemas, err := getServicePlanSchemas(session, d.Get("service_plan").(string))
 	if err != nil {
-		return nil, err
+		return err
 	}
+	paramsSchema := schemas.ServiceInstance.Update.Parameters
+	replaced := (d.Get("replace_on_service_plan_change").(bool) && d.HasChange("service_plan")) ||
+		(d.Get("replace_on_params_change").(bool) && d.HasChange("json_params"))
+	if d.Id() == "" || replaced {
+		paramsSchema = schemas.ServiceInstance.Create.Parameters
+	}
+	return validateParamsSchema("json_params", d.Get("json_params").(string), paramsSchema)
+}
 
-	d.Set("name", serviceinstance.Name)
-	d.Set("service_plan", serviceinstance.ServicePlanGUID)
-	d.Set("space", serviceinstance.SpaceGUID)
-	d.Set("tags", serviceinstance.Tags)
-
-	d.Set("replace_on_service_plan_change", false)
-	d.Set("replace_on_params_change", false)
+// serviceInstanceParamsRetrievable - check if service offering of a plan let params of its instances be fetched
+func serviceInstanceParamsRetrievable(session *managers.Session, planGUID string) (bool, error) {
+	var plan struct {
+		Included struct {
+			ServiceOfferings []struct {
+				InstancesRetrievable bool `json:"instances_retrievable"`
+			} `json:"service_offerings"`
+		} `json:"included"`
+	}
+	_, err := v3Do(session, "GET", fmt.Sprintf("/v3/service_plans/%s?include=service_offering", planGUID), nil, &plan)
+	if err != nil {
+		// plan may be not visible anymore (e.g. broker removed it), params are simply not read
+		if IsErrNotFound(err) || IsErrNotAuthorized(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	offerings := plan.Included.ServiceOfferings
+	return len(offerings) > 0 && offerings[0].InstancesRetrievable, nil
+}
 
-	return ImportReadContext(resourceServiceInstanceRead)(ctx, d, meta)
+// getServiceInstanceParams - fetch params of a service instance from its broker as a normalized json string
+func getServiceInstanceParams(session *managers.Session, guid string) (string, error) {
+	var params map[string]interface{}
+	_, err := v3Do(session, "GET", fmt.Sprintf("/v3/service_instances/%s/parameters", guid), nil, &params)
+	if err != nil {
+		return "", err
+	}
+	if params == nil {
+		params = make(map[string]interface{})
+	}
+	b, err := json.Marshal(params)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
 }
 
-func resourceServiceInstanceStateFunc(serviceInstanceID string, operationType string, meta interface{}) resource.StateRefreshFunc {
-	return func() (interface{}, string, error) {
-		session := meta.(*managers.Session)
-		serviceInstance, _, err := session.ClientV2.GetServiceInstance(serviceInstanceID)
-		if err != nil {
-			// We should get a 404 if the resource doesn't exist (eg. it has been deleted)
-			// In this case, the refresh code is expecting a nil object
-			if IsErrNotFound(err) {
-				return nil, "", nil
-			}
-			return nil, "", err
-		}
+func resourceServiceInstanceDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	session := meta.(*managers.Session)
+	id := d.Id()
 
-		if serviceInstance.LastOperation.Type == operationType {
-			stateStr := string(serviceInstance.LastOperation.State)
-			switch serviceInstance.LastOperation.State {
-			case constant.LastOperationSucceeded:
-				return serviceInstance, stateStr, nil
-			case constant.LastOperationFailed:
-				return nil, stateStr, fmt.Errorf("%s", serviceInstance.LastOperation.Description)
+	// cloud controller v3 api always deletes bindings, keep refusing it when recursive delete is not asked
+	if !d.Get("recursive_delete").(bool) {
+		for _, bindingType := range []string{"service_credential_bindings", "service_route_bindings"} {
+			var bindings struct {
+				Resources []json.RawMessage `json:"resources"`
+			}
+			path := fmt.Sprintf("/v3/%s?service_instance_guids=%s&per_page=1", bindingType, id)
+			_, err := v3Do(session, "GET", path, nil, &bindings)
+			if err != nil {
+				return diag.FromErr(err)
+			}
+			if len(bindings.Resources) > 0 {
+				return diag.Errorf("Service instance %s still has %s, set recursive_delete to delete them with the instance",
+					id, strings.ReplaceAll(bindingType, "_", " "))
 			}
-			return serviceInstance, stateStr, nil
 		}
+	}
 
-		return serviceInstance, "wrong operation", nil
+	path := fmt.Sprintf("/v3/service_instances/%s", id)
+	if session.PurgeWhenDelete {
+		path += "?purge=true"
+	}
+	jobPath, err := v3Do(session, "DELETE", path, nil, nil)
+	if err != nil {
+		if IsErrNotFound(err) {
+			return nil
+		}
+		return diag.FromErr(err)
+	}
+	if jobPath == "" {
+		return nil
+	}
+	job, err := v3PollJob(ctx, session, jobPath, d.Timeout(schema.TimeoutDelete))
+	if err != nil {
+		return serviceInstanceOperationDiags(session, id, job, err)
 	}
+	return job.WarningsDiags()
 }
 
-var resourceServiceInstancePendingStates = []string{
-	"in progress",
-}
+func resourceServiceInstanceImport(ctx context.Context, d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	d.Set("replace_on_service_plan_change", false)
+	d.Set("replace_on_params_change", false)
 
-var resourceServiceInstanceSuccessStates = []string{
-	"succeeded",
+	return ImportReadContext(resourceServiceInstanceRead)(ctx, d, meta)
 }
@@ -0,0 +1,142 @@
+package cloudfoundry
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"github.com/terraform-providers/terraform-provider-cloudfoundry/cloudfoundry/managers"
+)
+
+func resourceServiceInstanceShare() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceServiceInstanceShareUpdate,
+		ReadContext:   resourceServiceInstanceShareRead,
+		UpdateContext: resourceServiceInstanceShareUpdate,
+		DeleteContext: resourceServiceInstanceShareDelete,
+		Importer: &schema.ResourceImporter{
+			StateContext: ImportReadContext(resourceServiceInstanceShareRead),
+		},
+
+		Schema: map[string]*schema.Schema{
+			"service_instance": &schema.Schema{
+				Type:         schema.TypeString,
+				Required:     true,
+				ForceNew:     true,
+				ValidateFunc: validation.NoZeroValues,
+			},
+			"spaces": &schema.Schema{
+				Type:        schema.TypeSet,
+				Required:    true,
+				Description: "Space GUIDs where service instance is shared, any other share is removed",
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Set:         schema.HashString,
+			},
+		},
+	}
+}
+
+type v3ToManyRelationship struct {
+	Data []struct {
+		GUID string `json:"guid"`
+	} `json:"data"`
+}
+
+func pathSharedSpaces(serviceInstanceGUID string) string {
+	return fmt.Sprintf("/v3/service_instances/%s/relationships/shared_spaces", serviceInstanceGUID)
+}
+
+func getSharedSpaces(session *managers.Session, serviceInstanceGUID string) ([]string, error) {
+	var shared v3ToManyRelationship
+	_, err := v3Do(session, "GET", pathSharedSpaces(serviceInstanceGUID), nil, &shared)
+	if err != nil {
+		return nil, err
+	}
+	guids := make([]string, len(shared.Data))
+	for i, s := range shared.Data {
+		guids[i] = s.GUID
+	}
+	return guids, nil
+}
+
+func resourceServiceInstanceShareRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	session := meta.(*managers.Session)
+
+	spaces, err := getSharedSpaces(session, d.Id())
+	if err != nil {
+		if IsErrNotFound(err) {
+			d.SetId("")
+			return nil
+		}
+		return diag.FromErr(err)
+	}
+	d.Set("service_instance", d.Id())
+	d.Set("spaces", spaces)
+	return nil
+}
+
+func resourceServiceInstanceShareUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	session := meta.(*managers.Session)
+	guid := d.Get("service_instance").(string)
+
+	current, err := getSharedSpaces(session, guid)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	currentSet := make(map[string]bool)
+	for _, s := range current {
+		currentSet[s] = true
+	}
+	wanted := d.Get("spaces").(*schema.Set)
+
+	// shares made outside of terraform are reconciled too
+	for _, s := range current {
+		if wanted.Contains(s) {
+			continue
+		}
+		_, err := v3Do(session, "DELETE", fmt.Sprintf("%s/%s", pathSharedSpaces(guid), s), nil, nil)
+		if err != nil && !IsErrNotFound(err) {
+			return diag.FromErr(err)
+		}
+	}
+
+	var toShare v3ToManyRelationship
+	for _, s := range wanted.List() {
+		if currentSet[s.(string)] {
+			continue
+		}
+		toShare.Data = append(toShare.Data, struct {
+			GUID string `json:"guid"`
+		}{GUID: s.(string)})
+	}
+	if len(toShare.Data) > 0 {
+		_, err := v3Do(session, "POST", pathSharedSpaces(guid), toShare, nil)
+		if err != nil {
+			return diag.FromErr(err)
+		}
+	}
+
+	d.SetId(guid)
+	return resourceServiceInstanceShareRead(ctx, d, meta)
+}
+
+func resourceServiceInstanceShareDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	session := meta.(*managers.Session)
+
+	current, err := getSharedSpaces(session, d.Id())
+	if err != nil {
+		if IsErrNotFound(err) {
+			return nil
+		}
+		return diag.FromErr(err)
+	}
+	for _, s := range current {
+		_, err := v3Do(session, "DELETE", fmt.Sprintf("%s/%s", pathSharedSpaces(d.Id()), s), nil, nil)
+		if err != nil && !IsErrNotFound(err) {
+			return diag.FromErr(err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,121 @@
+package cloudfoundry
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+const serviceInstanceShareResource = `
+data "cloudfoundry_service" "test-service" {
+  name = "%s"
+}
+
+resource "cloudfoundry_space" "share-1" {
+  name = "share-1"
+  org = "%s"
+}
+
+resource "cloudfoundry_space" "share-2" {
+  name = "share-2"
+  org = "%s"
+}
+
+resource "cloudfoundry_service_instance" "test-service-instance-shared" {
+  name = "test-service-instance-shared"
+  space = "%s"
+  service_plan = "${data.cloudfoundry_service.test-service.service_plans["%s"]}"
+}
+
+resource "cloudfoundry_service_instance_share" "share" {
+  service_instance = cloudfoundry_service_instance.test-service-instance-shared.id
+  spaces = [ %s ]
+}
+`
+
+func TestAccResServiceInstanceShare_normal(t *testing.T) {
+
+	orgID, _ := defaultTestOrg(t)
+	spaceID, _ := defaultTestSpace(t)
+	serviceName1, _, servicePlan := getTestServiceBrokers(t)
+
+	ref := "cloudfoundry_service_instance_share.share"
+	config := func(spaces string) string {
+		return fmt.Sprintf(serviceInstanceShareResource,
+			serviceName1, orgID, orgID, spaceID, servicePlan, spaces)
+	}
+	resource.Test(t,
+		resource.TestCase{
+			PreCheck:          func() { testAccPreCheck(t) },
+			ProviderFactories: testAccProvidersFactories,
+			CheckDestroy: testAccCheckServiceInstanceDestroyed(
+				[]string{"test-service-instance-shared"},
+				"cloudfoundry_service_instance.test-service-instance-shared"),
+			Steps: []resource.TestStep{
+
+				resource.TestStep{
+					Config: config("cloudfoundry_space.share-1.id"),
+					Check: resource.ComposeTestCheckFunc(
+						testAccCheckServiceInstanceShared(ref, 1),
+						resource.TestCheckResourceAttr(ref, "spaces.#", "1"),
+					),
+				},
+
+				resource.TestStep{
+					Config: config("cloudfoundry_space.share-1.id, cloudfoundry_space.share-2.id"),
+					Check: resource.ComposeTestCheckFunc(
+						testAccCheckServiceInstanceShared(ref, 2),
+						resource.TestCheckResourceAttr(ref, "spaces.#", "2"),
+					),
+				},
+
+				resource.TestStep{
+					ResourceName:      ref,
+					ImportState:       true,
+					ImportStateVerify: true,
+				},
+
+				resource.TestStep{
+					Config: config("cloudfoundry_space.share-2.id"),
+					Check: resource.ComposeTestCheckFunc(
+						testAccCheckServiceInstanceShared(ref, 1),
+						resource.TestCheckResourceAttr(ref, "spaces.#", "1"),
+					),
+				},
+			},
+		})
+}
+
+func testAccCheckServiceInstanceShared(resource string, count int) resource.TestCheckFunc {
+
+	return func(s *terraform.State) error {
+
+		rs, ok := s.RootModule().Resources[resource]
+		if !ok {
+			return fmt.Errorf("service instance share '%s' not found in terraform state", resource)
+		}
+
+		spaces, err := getSharedSpaces(testSession(), rs.Primary.ID)
+		if err != nil {
+			return err
+		}
+		if len(spaces) != count {
+			return fmt.Errorf("expected service instance to be shared in %d spaces but found %d", count, len(spaces))
+		}
+		for _, space := range spaces {
+			found := false
+			for k, v := range rs.Primary.Attributes {
+				if k != "spaces.#" && strings.HasPrefix(k, "spaces.") && v == space {
+					found = true
+				}
+			}
+			if !found {
+				return fmt.Errorf("space %s is shared but not found in terraform state", space)
+			}
+		}
+		return nil
+	}
+}
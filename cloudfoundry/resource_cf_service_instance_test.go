@@ -1,14 +1,21 @@
 package cloudfoundry
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
 	"fmt"
+	"net/http"
 	"testing"
+	"time"
 
 	"code.cloudfoundry.org/cli/api/cloudcontroller/ccv2"
 	"code.cloudfoundry.org/cli/api/cloudcontroller/ccv2/constant"
 	"github.com/terraform-providers/terraform-provider-cloudfoundry/cloudfoundry/managers"
 
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
 )
 
@@ -38,6 +45,19 @@ resource "cloudfoundry_service_instance" "test-service-instance" {
 }
 `
 
+const serviceInstanceResourceParams = `
+data "cloudfoundry_service" "test-service" {
+  name = "%s"
+}
+
+resource "cloudfoundry_service_instance" "test-service-instance-params" {
+  name = "test-service-instance-params"
+  space = "%s"
+  service_plan = "${data.cloudfoundry_service.test-service.service_plans["%s"]}"
+  json_params = jsonencode({ "param-1" = "value-1" })
+}
+`
+
 const serviceInstanceResourceAsyncCreate = `
 data "cloudfoundry_service" "fake-service" {
   name = "${keys(cloudfoundry_service_broker.fake-service-broker.services)[0]}"
@@ -60,6 +80,22 @@ resource "cloudfoundry_service_instance" "fake-service-instance-with-fake-async-
 %s
 `
 
+const serviceInstanceResourceUpgrade = `
+data "cloudfoundry_service" "fake-service" {
+  name = "${keys(cloudfoundry_service_broker.fake-service-broker.services)[0]}"
+}
+
+resource "cloudfoundry_service_instance" "fake-service-instance-upgrade" {
+  name = "fake-service-instance-upgrade"
+  space = "%s"
+  service_plan = "${data.cloudfoundry_service.fake-service.service_plans["fake-plan"]}"
+  auto_upgrade = true
+  depends_on = ["cloudfoundry_service_broker.fake-service-broker"]
+}
+
+%s
+`
+
 const fakeServiceBroker = `
 
 data "cloudfoundry_domain" "fake-service-broker-domain" {
@@ -119,6 +155,8 @@ func TestAccResServiceInstance_normal(t *testing.T) {
 						testAccCheckServiceInstanceExists(ref),
 						resource.TestCheckResourceAttr(
 							ref, "name", "test-service-instance"),
+						resource.TestCheckResourceAttr(
+							ref, "upgrade_available", "false"),
 						resource.TestCheckResourceAttr(
 							ref, "tags.#", "2"),
 						resource.TestCheckResourceAttr(
@@ -150,6 +188,92 @@ func TestAccResServiceInstance_normal(t *testing.T) {
 		})
 }
 
+func TestAccResServiceInstance_paramsDrift(t *testing.T) {
+
+	spaceId, _ := defaultTestSpace(t)
+	serviceName1, _, servicePlan := getTestServiceBrokers(t)
+
+	var offerings struct {
+		Resources []struct {
+			InstancesRetrievable bool `json:"instances_retrievable"`
+		} `json:"resources"`
+	}
+	_, err := v3Do(testSession(), "GET", "/v3/service_offerings?names="+serviceName1, nil, &offerings)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(offerings.Resources) == 0 || !offerings.Resources[0].InstancesRetrievable {
+		t.Skipf("service offering %s does not allow retrieving instance params", serviceName1)
+	}
+
+	ref := "cloudfoundry_service_instance.test-service-instance-params"
+	config := fmt.Sprintf(serviceInstanceResourceParams, serviceName1, spaceId, servicePlan)
+	resource.Test(t,
+		resource.TestCase{
+			PreCheck:          func() { testAccPreCheck(t) },
+			ProviderFactories: testAccProvidersFactories,
+			CheckDestroy: testAccCheckServiceInstanceDestroyed(
+				[]string{"test-service-instance-params"},
+				ref),
+			Steps: []resource.TestStep{
+
+				resource.TestStep{
+					Config: config,
+					Check: resource.ComposeTestCheckFunc(
+						testAccCheckServiceInstanceExists(ref),
+						resource.TestCheckResourceAttr(
+							ref, "json_params", `{"param-1":"value-1"}`),
+					),
+				},
+
+				resource.TestStep{
+					// params changed outside of terraform must be shown as a drift
+					PreConfig: func() {
+						sis, _, err := testSession().ClientV2.GetServiceInstances(
+							ccv2.FilterByName("test-service-instance-params"),
+							ccv2.FilterEqual(constant.SpaceGUIDFilter, spaceId),
+						)
+						if err != nil || len(sis) == 0 {
+							t.Fatalf("service instance not found: %v", err)
+						}
+						jobPath, err := v3Do(testSession(), "PATCH", "/v3/service_instances/"+sis[0].GUID, map[string]interface{}{
+							"parameters": map[string]interface{}{"param-1": "drifted"},
+						}, nil)
+						if err != nil {
+							t.Fatal(err)
+						}
+						if jobPath != "" {
+							_, err = v3PollJob(context.Background(), testSession(), jobPath, 5*time.Minute)
+							if err != nil {
+								t.Fatal(err)
+							}
+						}
+					},
+					Config:             config,
+					PlanOnly:           true,
+					ExpectNonEmptyPlan: true,
+				},
+
+				resource.TestStep{
+					Config: config,
+					Check: resource.ComposeTestCheckFunc(
+						resource.TestCheckResourceAttr(
+							ref, "json_params", `{"param-1":"value-1"}`),
+					),
+				},
+
+				resource.TestStep{
+					ResourceName:      ref,
+					ImportState:       true,
+					ImportStateVerify: true,
+					ImportStateVerifyIgnore: []string{
+						"recursive_delete",
+					},
+				},
+			},
+		})
+}
+
 func TestAccResServiceInstances_withFakePlans(t *testing.T) {
 
 	spaceId, _ := defaultTestSpace(t)
@@ -186,6 +310,108 @@ func TestAccResServiceInstances_withFakePlans(t *testing.T) {
 		})
 }
 
+func TestAccResServiceInstance_upgradeWithFakeBroker(t *testing.T) {
+
+	spaceId, _ := defaultTestSpace(t)
+	appDomain := defaultAppDomain()
+
+	ref := "cloudfoundry_service_instance.fake-service-instance-upgrade"
+	broker := fmt.Sprintf(fakeServiceBroker, appDomain, spaceId, spaceId, appDomain, spaceId)
+	config := fmt.Sprintf(serviceInstanceResourceUpgrade, spaceId, broker)
+	resource.Test(t,
+		resource.TestCase{
+			PreCheck:          func() { testAccPreCheck(t) },
+			ProviderFactories: testAccProvidersFactories,
+			CheckDestroy: testAccCheckServiceInstanceDestroyed(
+				[]string{"fake-service-instance-upgrade"},
+				ref),
+			Steps: []resource.TestStep{
+
+				resource.TestStep{
+					Config: broker,
+				},
+
+				resource.TestStep{
+					PreConfig: func() {
+						configureFakeServiceBroker(t, appDomain, fakePlanMaintenanceInfo("1.0.0"))
+					},
+					Config: config,
+					Check: resource.ComposeTestCheckFunc(
+						testAccCheckServiceInstanceExists(ref),
+						resource.TestCheckResourceAttr(ref, "maintenance_info_version", "1.0.0"),
+						resource.TestCheckResourceAttr(ref, "upgrade_available", "false"),
+					),
+				},
+
+				resource.TestStep{
+					// broker publishes a new version, upgrade is only known once catalog has been fetched again
+					PreConfig: func() {
+						configureFakeServiceBroker(t, appDomain, fakePlanMaintenanceInfo("2.0.0"))
+					},
+					Config:             config,
+					ExpectNonEmptyPlan: true,
+				},
+
+				resource.TestStep{
+					Config: config,
+					Check: resource.ComposeTestCheckFunc(
+						resource.TestCheckResourceAttr(ref, "maintenance_info_version", "2.0.0"),
+						resource.TestCheckResourceAttr(ref, "upgrade_available", "false"),
+					),
+				},
+			},
+		})
+}
+
+// configureFakeServiceBroker - change behaviors (e.g. catalog) of fake service broker deployed by fakeServiceBroker
+func configureFakeServiceBroker(t *testing.T, appDomain string, f func(behaviors map[string]interface{})) {
+	configURL := fmt.Sprintf("https://fake-service-broker.%s/config", appDomain)
+	client := testSession().HttpClient
+
+	resp, err := client.Get(configURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	var config struct {
+		Behaviors map[string]interface{} `json:"behaviors"`
+	}
+	err = json.NewDecoder(resp.Body).Decode(&config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f(config.Behaviors)
+
+	b, err := json.Marshal(map[string]interface{}{"behaviors": config.Behaviors})
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err = client.Post(configURL, "application/json", bytes.NewReader(b))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("fake service broker refused config with status %d", resp.StatusCode)
+	}
+}
+
+// fakePlanMaintenanceInfo - publish version as maintenance info of fake-plan in catalog of fake service broker
+func fakePlanMaintenanceInfo(version string) func(behaviors map[string]interface{}) {
+	return func(behaviors map[string]interface{}) {
+		catalog := behaviors["catalog"].(map[string]interface{})["body"].(map[string]interface{})
+		for _, service := range catalog["services"].([]interface{}) {
+			for _, plan := range service.(map[string]interface{})["plans"].([]interface{}) {
+				if plan.(map[string]interface{})["name"] == "fake-plan" {
+					plan.(map[string]interface{})["maintenance_info"] = map[string]interface{}{
+						"version": version,
+					}
+				}
+			}
+		}
+	}
+}
+
 func testAccCheckServiceInstanceExists(resource string) resource.TestCheckFunc {
 
 	return func(s *terraform.State) error {
@@ -228,3 +454,301 @@ func testAccCheckServiceInstanceDestroyed(names []string, testResource string) r
 		return nil
 	}
 }
+
+// fakeCC - in memory cloud controller serving one service instance and its plans on v3 api,
+// broker operations are done at once and answered with a complete or failed job
+type fakeCC struct {
+	fakeServer
+	instance map[string]interface{}
+	params   map[string]interface{}
+	// maintenance info version of each plan
+	plans map[string]string
+	// schema of binding params published for plans
+	bindingSchema map[string]interface{}
+	// status answered to PATCH of instance, 0 when accepted
+	patchStatus int
+	// status answered when fetching params of instance, 0 when given back
+	paramsStatus int
+	// broker fails operations done by job
+	failJob bool
+	patches []map[string]interface{}
+}
+
+func newFakeCC() *fakeCC {
+	f := &fakeCC{
+		instance: map[string]interface{}{
+			"guid": "si-guid",
+			"name": "fake-si",
+			"type": "managed",
+			"tags": []interface{}{"tag-1"},
+			"maintenance_info": map[string]interface{}{
+				"version": "1.0.0",
+			},
+			"upgrade_available": false,
+			"relationships": map[string]interface{}{
+				"space":        newV3Relationship("space-guid"),
+				"service_plan": newV3Relationship("plan-1"),
+			},
+		},
+		params: map[string]interface{}{"param-1": "value-1"},
+		plans:  map[string]string{"plan-1": "1.0.0", "plan-2": "1.0.0"},
+	}
+	f.start(f.serve)
+	return f
+}
+
+func (f *fakeCC) serve(w http.ResponseWriter, r *http.Request, parts []string) {
+	switch {
+	case len(parts) == 3 && parts[1] == "service_plans":
+		version, ok := f.plans[parts[2]]
+		if !ok {
+			f.writeJSON(w, http.StatusNotFound, nil)
+			return
+		}
+		f.writeJSON(w, http.StatusOK, map[string]interface{}{
+			"guid":             parts[2],
+			"maintenance_info": map[string]interface{}{"version": version},
+			"schemas": map[string]interface{}{
+				"service_binding": map[string]interface{}{
+					"create": map[string]interface{}{"parameters": f.bindingSchema},
+				},
+			},
+			"included": map[string]interface{}{
+				"service_offerings": []interface{}{
+					map[string]interface{}{"instances_retrievable": true},
+				},
+			},
+		})
+	case len(parts) == 3 && parts[1] == "jobs":
+		state := "COMPLETE"
+		if parts[2] == "failed" {
+			state = "FAILED"
+		}
+		f.writeJSON(w, http.StatusOK, map[string]interface{}{
+			"guid":   parts[2],
+			"state":  state,
+			"errors": []interface{}{map[string]interface{}{"detail": "Service broker error: internal error"}},
+		})
+	case len(parts) == 3 && parts[1] == "service_instances" && r.Method == "GET":
+		f.writeJSON(w, http.StatusOK, f.instance)
+	case len(parts) == 4 && parts[3] == "parameters":
+		if f.paramsStatus != 0 {
+			f.writeError(w, f.paramsStatus, "broker is not available")
+			return
+		}
+		f.writeJSON(w, http.StatusOK, f.params)
+	case len(parts) == 3 && parts[1] == "service_instances" && r.Method == "PATCH":
+		var body map[string]interface{}
+		if !f.readJSON(w, r, &body) {
+			return
+		}
+		f.patches = append(f.patches, body)
+		if f.patchStatus != 0 {
+			f.writeError(w, f.patchStatus, "request rejected")
+			return
+		}
+		if f.failJob {
+			w.Header().Set("Location", f.server.URL+"/v3/jobs/failed")
+			f.writeJSON(w, http.StatusAccepted, nil)
+			return
+		}
+		for _, k := range []string{"name", "tags"} {
+			if v, ok := body[k]; ok {
+				f.instance[k] = v
+			}
+		}
+		if v, ok := body["parameters"]; ok {
+			f.params = v.(map[string]interface{})
+		}
+		if v, ok := body["relationships"]; ok {
+			f.instance["relationships"].(map[string]interface{})["service_plan"] = v.(map[string]interface{})["service_plan"]
+		}
+		if v, ok := body["maintenance_info"]; ok {
+			f.instance["maintenance_info"] = v
+			f.instance["upgrade_available"] = false
+		}
+		w.Header().Set("Location", f.server.URL+"/v3/jobs/complete")
+		f.writeJSON(w, http.StatusAccepted, nil)
+	default:
+		f.writeJSON(w, http.StatusNotFound, nil)
+	}
+}
+
+// fakeCCServiceInstanceState - state of service instance served by fake cloud controller as after last apply
+func fakeCCServiceInstanceState(t *testing.T, session *managers.Session) *terraform.InstanceState {
+	r := resourceServiceInstance()
+	d := schema.TestResourceDataRaw(t, r.Schema, map[string]interface{}{
+		"name":         "fake-si",
+		"space":        "space-guid",
+		"service_plan": "plan-1",
+		"json_params":  `{"param-1":"value-1"}`,
+		"tags":         []interface{}{"tag-1"},
+	})
+	d.SetId("si-guid")
+	if diags := r.ReadContext(context.Background(), d, session); diags.HasError() {
+		t.Fatalf("read failed: %v", diags)
+	}
+	return d.State()
+}
+
+func TestServiceInstanceUpdate_failureKeepsState(t *testing.T) {
+	for _, failure := range []string{"request", "job"} {
+		t.Run(failure, func(t *testing.T) {
+			cc := newFakeCC()
+			defer cc.server.Close()
+			session := cc.session()
+			ctx := context.Background()
+
+			r := resourceServiceInstance()
+			state := fakeCCServiceInstanceState(t, session)
+			diff, err := r.Diff(ctx, state, terraform.NewResourceConfigRaw(map[string]interface{}{
+				"name":         "fake-si-renamed",
+				"space":        "space-guid",
+				"service_plan": "plan-2",
+				"json_params":  `{"param-1":"value-2"}`,
+				"tags":         []interface{}{"tag-2"},
+			}), session)
+			if err != nil {
+				t.Fatalf("diff failed: %s", err)
+			}
+
+			if failure == "request" {
+				cc.patchStatus = http.StatusBadGateway
+			} else {
+				cc.failJob = true
+			}
+			newState, diags := r.Apply(ctx, state, diff, session)
+			if !diags.HasError() {
+				t.Fatalf("expected update to fail")
+			}
+			if len(cc.patches) != 1 {
+				t.Fatalf("expected one update request but found %d", len(cc.patches))
+			}
+			// nothing has been applied, next plan must show every change again
+			for k, v := range map[string]string{
+				"name":         "fake-si",
+				"service_plan": "plan-1",
+				"json_params":  `{"param-1":"value-1"}`,
+				"tags.#":       "1",
+				"tags.0":       "tag-1",
+			} {
+				if newState.Attributes[k] != v {
+					t.Fatalf("expected %s to be kept as '%s' but found '%s'", k, v, newState.Attributes[k])
+				}
+			}
+		})
+	}
+}
+
+func TestServiceInstanceRead_paramsNotAvailable(t *testing.T) {
+	cc := newFakeCC()
+	defer cc.server.Close()
+	session := cc.session()
+
+	r := resourceServiceInstance()
+	d := r.Data(fakeCCServiceInstanceState(t, session))
+	cc.params = map[string]interface{}{"param-1": "drifted"}
+	cc.paramsStatus = http.StatusBadGateway
+	diags := r.ReadContext(context.Background(), d, session)
+	if diags.HasError() {
+		t.Fatalf("expected read to succeed when broker is not available: %v", diags)
+	}
+	if len(diags) != 1 || diags[0].Severity != diag.Warning {
+		t.Fatalf("expected one warning but found %v", diags)
+	}
+	if v := d.Get("json_params").(string); v != `{"param-1":"value-1"}` {
+		t.Fatalf("expected params in state to be kept but found '%s'", v)
+	}
+}
+
+func TestServiceInstanceUpdate_paramsRemoved(t *testing.T) {
+	cc := newFakeCC()
+	defer cc.server.Close()
+	session := cc.session()
+	ctx := context.Background()
+
+	r := resourceServiceInstance()
+	state := fakeCCServiceInstanceState(t, session)
+	config := terraform.NewResourceConfigRaw(map[string]interface{}{
+		"name":         "fake-si",
+		"space":        "space-guid",
+		"service_plan": "plan-1",
+		"tags":         []interface{}{"tag-1"},
+	})
+	diff, err := r.Diff(ctx, state, config, session)
+	if err != nil {
+		t.Fatalf("diff failed: %s", err)
+	}
+	state, diags := r.Apply(ctx, state, diff, session)
+	if diags.HasError() {
+		t.Fatalf("update failed: %v", diags)
+	}
+	if len(cc.patches) != 1 || fmt.Sprint(cc.patches[0]["parameters"]) != "map[]" {
+		t.Fatalf("expected params to be cleared on broker but found updates %v", cc.patches)
+	}
+
+	// broker gives back default params, they are not managed and must not show a diff
+	cc.params = map[string]interface{}{"default-param": "default"}
+	state, diags = r.RefreshWithoutUpgrade(ctx, state, session)
+	if diags.HasError() {
+		t.Fatalf("refresh failed: %v", diags)
+	}
+	if v := state.Attributes["json_params"]; v != "" {
+		t.Fatalf("expected params to be left unset but found '%s'", v)
+	}
+	diff, err = r.Diff(ctx, state, config, session)
+	if err != nil {
+		t.Fatalf("diff failed: %s", err)
+	}
+	if !diff.Empty() {
+		t.Fatalf("expected no diff but found %v", diff)
+	}
+}
+
+func TestServiceInstanceUpdate_autoUpgrade(t *testing.T) {
+	cc := newFakeCC()
+	defer cc.server.Close()
+	session := cc.session()
+	ctx := context.Background()
+
+	r := resourceServiceInstance()
+	state := fakeCCServiceInstanceState(t, session)
+	cfg := terraform.NewResourceConfigRaw(map[string]interface{}{
+		"name":         "fake-si",
+		"space":        "space-guid",
+		"service_plan": "plan-1",
+		"json_params":  `{"param-1":"value-1"}`,
+		"tags":         []interface{}{"tag-1"},
+		"auto_upgrade": true,
+	})
+
+	// broker publishes a new maintenance info version for plan of instance
+	cc.plans["plan-1"] = "2.0.0"
+	cc.instance["upgrade_available"] = true
+	state, diags := r.RefreshWithoutUpgrade(ctx, state, session)
+	if diags.HasError() {
+		t.Fatalf("refresh failed: %v", diags)
+	}
+	if state.Attributes["upgrade_available"] != "true" {
+		t.Fatalf("expected upgrade to be available")
+	}
+	diff, err := r.Diff(ctx, state, cfg, session)
+	if err != nil {
+		t.Fatalf("diff failed: %s", err)
+	}
+	if diff == nil || diff.Attributes["maintenance_info_version"] == nil || !diff.Attributes["maintenance_info_version"].NewComputed {
+		t.Fatalf("expected upgrade to be planned but found diff %v", diff)
+	}
+
+	newState, diags := r.Apply(ctx, state, diff, session)
+	if diags.HasError() {
+		t.Fatalf("upgrade failed: %v", diags)
+	}
+	last := cc.patches[len(cc.patches)-1]
+	if info, ok := last["maintenance_info"].(map[string]interface{}); !ok || info["version"] != "2.0.0" || len(last) != 1 {
+		t.Fatalf("expected upgrade to version 2.0.0 on its own but found request %v", last)
+	}
+	if newState.Attributes["maintenance_info_version"] != "2.0.0" || newState.Attributes["upgrade_available"] != "false" {
+		t.Fatalf("unexpected state after upgrade: %v", newState.Attributes)
+	}
+}
@@ -54,6 +54,13 @@ func resourceServiceKey() *schema.Resource {
 				Computed: true,
 			},
 		},
+
+		CustomizeDiff: func(ctx context.Context, diff *schema.ResourceDiff, meta interface{}) error {
+			if diff.Id() != "" && !diff.HasChange("params") && !diff.HasChange("params_json") && !diff.HasChange("service_instance") {
+				return nil
+			}
+			return bindingParamsCustomizeDiff(diff, meta.(*managers.Session), "service_instance", "params", "params_json")
+		},
 	}
 }
 
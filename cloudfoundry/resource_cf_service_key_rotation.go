@@ -0,0 +1,281 @@
+package cloudfoundry
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"code.cloudfoundry.org/cli/api/cloudcontroller/ccv2"
+	"code.cloudfoundry.org/cli/api/cloudcontroller/ccv2/constant"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"github.com/terraform-providers/terraform-provider-cloudfoundry/cloudfoundry/managers"
+)
+
+// rotationKeyTimeFormat - suffix of keys names, it sorts as time does
+const rotationKeyTimeFormat = "20060102150405.000"
+
+func resourceServiceKeyRotation() *schema.Resource {
+
+	return &schema.Resource{
+
+		CreateContext: resourceServiceKeyRotationCreate,
+		ReadContext:   resourceServiceKeyRotationRead,
+		UpdateContext: resourceServiceKeyRotationUpdate,
+		DeleteContext: resourceServiceKeyRotationDelete,
+
+		Importer: &schema.ResourceImporter{
+			StateContext: resourceServiceKeyRotationImport,
+		},
+
+		Schema: map[string]*schema.Schema{
+
+			"service_instance": &schema.Schema{
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+			"name_prefix": &schema.Schema{
+				Type:         schema.TypeString,
+				Required:     true,
+				ForceNew:     true,
+				Description:  "Keys are named <name_prefix>-<creation time>, any key with such name is managed by this resource",
+				ValidateFunc: validation.NoZeroValues,
+			},
+			"rotation_trigger": &schema.Schema{
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "Any change of this value creates a new key",
+			},
+			"keep": &schema.Schema{
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      2,
+				Description:  "Number of keys kept alive, current one included, older ones are deleted",
+				ValidateFunc: validation.IntAtLeast(1),
+			},
+			"params": &schema.Schema{
+				Type:          schema.TypeMap,
+				Optional:      true,
+				Sensitive:     true,
+				ConflictsWith: []string{"params_json"},
+			},
+			"params_json": &schema.Schema{
+				Type:          schema.TypeString,
+				Optional:      true,
+				Sensitive:     true,
+				ConflictsWith: []string{"params"},
+				ValidateFunc:  validation.StringIsJSON,
+			},
+			"current_key": &schema.Schema{
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"keys": &schema.Schema{
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "GUIDs of keys alive, newest first",
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+			"credentials": &schema.Schema{
+				Type:      schema.TypeMap,
+				Computed:  true,
+				Sensitive: true,
+			},
+			"previous_credentials": &schema.Schema{
+				Type:      schema.TypeMap,
+				Computed:  true,
+				Sensitive: true,
+			},
+		},
+
+		CustomizeDiff: func(ctx context.Context, diff *schema.ResourceDiff, meta interface{}) error {
+			if diff.Id() == "" {
+				return bindingParamsCustomizeDiff(diff, meta.(*managers.Session), "service_instance", "params", "params_json")
+			}
+			if serviceKeyRotationNeeded(diff) {
+				err := bindingParamsCustomizeDiff(diff, meta.(*managers.Session), "service_instance", "params", "params_json")
+				if err != nil {
+					return err
+				}
+				for _, k := range []string{"current_key", "keys", "credentials", "previous_credentials"} {
+					if err := diff.SetNewComputed(k); err != nil {
+						return err
+					}
+				}
+				return nil
+			}
+			if diff.HasChange("keep") {
+				return diff.SetNewComputed("keys")
+			}
+			return nil
+		},
+	}
+}
+
+func serviceKeyRotationNeeded(d ResourceChanger) bool {
+	return d.HasChange("rotation_trigger") || d.HasChange("params") || d.HasChange("params_json")
+}
+
+// getRotatedServiceKeys - keys of service instance managed by a rotation, newest first
+func getRotatedServiceKeys(session *managers.Session, serviceInstanceGUID, namePrefix string) ([]ccv2.ServiceKey, error) {
+	keys, _, err := session.ClientV2.GetServiceKeys(ccv2.FilterEqual(constant.ServiceInstanceGUIDFilter, serviceInstanceGUID))
+	if err != nil {
+		return nil, err
+	}
+	rotated := make([]ccv2.ServiceKey, 0)
+	for _, key := range keys {
+		if !strings.HasPrefix(key.Name, namePrefix+"-") {
+			continue
+		}
+		if _, err := time.Parse(rotationKeyTimeFormat, strings.TrimPrefix(key.Name, namePrefix+"-")); err != nil {
+			continue
+		}
+		rotated = append(rotated, key)
+	}
+	sort.Slice(rotated, func(i, j int) bool {
+		return rotated[i].Name > rotated[j].Name
+	})
+	return rotated, nil
+}
+
+func createRotatedServiceKey(d *schema.ResourceData, session *managers.Session) error {
+	params := d.Get("params").(map[string]interface{})
+	paramJson := d.Get("params_json").(string)
+	if len(params) == 0 && paramJson != "" {
+		err := json.Unmarshal([]byte(paramJson), &params)
+		if err != nil {
+			return err
+		}
+	}
+	name := fmt.Sprintf("%s-%s", d.Get("name_prefix").(string), time.Now().UTC().Format(rotationKeyTimeFormat))
+	key, _, err := session.ClientV2.CreateServiceKey(d.Get("service_instance").(string), name, params)
+	if err != nil {
+		return err
+	}
+	log.Printf("[INFO] Service key %s (%s) created by rotation", key.Name, key.GUID)
+	return nil
+}
+
+// pruneRotatedServiceKeys - delete keys older than the number of keys to keep
+func pruneRotatedServiceKeys(d *schema.ResourceData, session *managers.Session) error {
+	keys, err := getRotatedServiceKeys(session, d.Get("service_instance").(string), d.Get("name_prefix").(string))
+	if err != nil {
+		return err
+	}
+	keep := d.Get("keep").(int)
+	if len(keys) <= keep {
+		return nil
+	}
+	for _, key := range keys[keep:] {
+		log.Printf("[INFO] Deleting service key %s (%s) out of rotation", key.Name, key.GUID)
+		_, err := session.ClientV2.DeleteServiceKey(key.GUID)
+		if err != nil && !IsErrNotFound(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+func resourceServiceKeyRotationCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	session := meta.(*managers.Session)
+
+	err := createRotatedServiceKey(d, session)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	d.SetId(fmt.Sprintf("%s/%s", d.Get("service_instance").(string), d.Get("name_prefix").(string)))
+	err = pruneRotatedServiceKeys(d, session)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	return resourceServiceKeyRotationRead(ctx, d, meta)
+}
+
+func resourceServiceKeyRotationRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	session := meta.(*managers.Session)
+
+	keys, err := getRotatedServiceKeys(session, d.Get("service_instance").(string), d.Get("name_prefix").(string))
+	if err != nil {
+		if IsErrNotFound(err) {
+			d.SetId("")
+			return nil
+		}
+		return diag.FromErr(err)
+	}
+	if len(keys) == 0 {
+		d.SetId("")
+		return nil
+	}
+	guids := make([]string, len(keys))
+	for i, key := range keys {
+		guids[i] = key.GUID
+	}
+	d.Set("keys", guids)
+	d.Set("current_key", keys[0].GUID)
+	d.Set("credentials", normalizeMap(keys[0].Credentials, make(map[string]interface{}), "", "_"))
+	previous := make(map[string]interface{})
+	if len(keys) > 1 {
+		previous = normalizeMap(keys[1].Credentials, make(map[string]interface{}), "", "_")
+	}
+	d.Set("previous_credentials", previous)
+	return nil
+}
+
+func resourceServiceKeyRotationUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	session := meta.(*managers.Session)
+
+	if serviceKeyRotationNeeded(d) {
+		err := createRotatedServiceKey(d, session)
+		if err != nil {
+			return diag.FromErr(err)
+		}
+	}
+	err := pruneRotatedServiceKeys(d, session)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	return resourceServiceKeyRotationRead(ctx, d, meta)
+}
+
+func resourceServiceKeyRotationDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	session := meta.(*managers.Session)
+
+	keys, err := getRotatedServiceKeys(session, d.Get("service_instance").(string), d.Get("name_prefix").(string))
+	if err != nil {
+		if IsErrNotFound(err) {
+			return nil
+		}
+		return diag.FromErr(err)
+	}
+	for _, key := range keys {
+		_, err := session.ClientV2.DeleteServiceKey(key.GUID)
+		if err != nil && !IsErrNotFound(err) {
+			return diag.FromErr(err)
+		}
+	}
+	return nil
+}
+
+func resourceServiceKeyRotationImport(ctx context.Context, d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	parts := strings.SplitN(d.Id(), "/", 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return nil, fmt.Errorf("Expected id in the form <service instance guid>/<name prefix> but got %s", d.Id())
+	}
+	d.Set("service_instance", parts[0])
+	d.Set("name_prefix", parts[1])
+	d.Set("keep", 2)
+	diags := resourceServiceKeyRotationRead(ctx, d, meta)
+	if diags.HasError() {
+		return nil, fmt.Errorf("%s", diags[0].Summary)
+	}
+	if d.Id() == "" {
+		return nil, fmt.Errorf("No service key named %s-<time> found on service instance %s", parts[1], parts[0])
+	}
+	return []*schema.ResourceData{d}, nil
+}
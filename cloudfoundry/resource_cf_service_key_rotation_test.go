@@ -0,0 +1,131 @@
+package cloudfoundry
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+const serviceKeyRotationResource = `
+data "cloudfoundry_service" "test-service" {
+  name = "%s"
+}
+
+resource "cloudfoundry_service_instance" "test-service-instance-rotated" {
+  name = "test-service-instance-rotated"
+  space = "%s"
+  service_plan = "${data.cloudfoundry_service.test-service.service_plans["%s"]}"
+}
+
+resource "cloudfoundry_service_key_rotation" "rotation" {
+  service_instance = cloudfoundry_service_instance.test-service-instance-rotated.id
+  name_prefix = "rotated"
+  rotation_trigger = "%s"
+  keep = 2
+}
+`
+
+func TestAccResServiceKeyRotation_normal(t *testing.T) {
+
+	spaceID, _ := defaultTestSpace(t)
+	serviceName1, _, servicePlan := getTestServiceBrokers(t)
+
+	ref := "cloudfoundry_service_key_rotation.rotation"
+	config := func(trigger string) string {
+		return fmt.Sprintf(serviceKeyRotationResource, serviceName1, spaceID, servicePlan, trigger)
+	}
+	var firstKey, secondKey string
+
+	resource.Test(t,
+		resource.TestCase{
+			PreCheck:          func() { testAccPreCheck(t) },
+			ProviderFactories: testAccProvidersFactories,
+			CheckDestroy:      testAccCheckServiceKeyRotationDestroyed(ref),
+			Steps: []resource.TestStep{
+
+				resource.TestStep{
+					Config: config("2026-01"),
+					Check: resource.ComposeTestCheckFunc(
+						testAccCheckServiceKeyRotationKeys(ref, 1, &firstKey),
+						resource.TestCheckResourceAttr(ref, "keys.#", "1"),
+						resource.TestCheckResourceAttr(ref, "previous_credentials.%", "0"),
+					),
+				},
+
+				resource.TestStep{
+					Config: config("2026-04"),
+					Check: resource.ComposeTestCheckFunc(
+						testAccCheckServiceKeyRotationKeys(ref, 2, &secondKey),
+						resource.TestCheckResourceAttr(ref, "keys.#", "2"),
+						resource.TestCheckResourceAttrPtr(ref, "keys.1", &firstKey),
+					),
+				},
+
+				resource.TestStep{
+					Config: config("2026-07"),
+					Check: resource.ComposeTestCheckFunc(
+						testAccCheckServiceKeyRotationKeys(ref, 2, nil),
+						resource.TestCheckResourceAttr(ref, "keys.#", "2"),
+						resource.TestCheckResourceAttrPtr(ref, "keys.1", &secondKey),
+					),
+				},
+
+				resource.TestStep{
+					ResourceName:            ref,
+					ImportState:             true,
+					ImportStateVerify:       true,
+					ImportStateVerifyIgnore: []string{"rotation_trigger"},
+				},
+			},
+		})
+}
+
+func testAccCheckServiceKeyRotationKeys(resource string, count int, current *string) resource.TestCheckFunc {
+
+	return func(s *terraform.State) error {
+
+		rs, ok := s.RootModule().Resources[resource]
+		if !ok {
+			return fmt.Errorf("service key rotation '%s' not found in terraform state", resource)
+		}
+		attributes := rs.Primary.Attributes
+		keys, err := getRotatedServiceKeys(testSession(), attributes["service_instance"], attributes["name_prefix"])
+		if err != nil {
+			return err
+		}
+		if len(keys) != count {
+			return fmt.Errorf("expected %d service keys alive but found %d", count, len(keys))
+		}
+		if err = assertEquals(attributes, "current_key", keys[0].GUID); err != nil {
+			return err
+		}
+		if current != nil {
+			*current = keys[0].GUID
+		}
+		return nil
+	}
+}
+
+func testAccCheckServiceKeyRotationDestroyed(resource string) resource.TestCheckFunc {
+
+	return func(s *terraform.State) error {
+
+		rs, ok := s.RootModule().Resources[resource]
+		if !ok {
+			return nil
+		}
+		keys, err := getRotatedServiceKeys(testSession(), rs.Primary.Attributes["service_instance"], rs.Primary.Attributes["name_prefix"])
+		if err != nil {
+			if IsErrNotFound(err) {
+				return nil
+			}
+			return err
+		}
+		if len(keys) > 0 {
+			return fmt.Errorf("service keys of rotation %s still exist", rs.Primary.ID)
+		}
+		return nil
+	}
+}
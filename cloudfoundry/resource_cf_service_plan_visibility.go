@@ -0,0 +1,163 @@
+package cloudfoundry
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"github.com/terraform-providers/terraform-provider-cloudfoundry/cloudfoundry/managers"
+)
+
+const (
+	planVisibilityAdmin        = "admin"
+	planVisibilityPublic       = "public"
+	planVisibilityOrganization = "organization"
+	planVisibilitySpace        = "space"
+)
+
+func resourceServicePlanVisibility() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceServicePlanVisibilityUpdate,
+		ReadContext:   resourceServicePlanVisibilityRead,
+		UpdateContext: resourceServicePlanVisibilityUpdate,
+		DeleteContext: resourceServicePlanVisibilityDelete,
+		Importer: &schema.ResourceImporter{
+			StateContext: ImportReadContext(resourceServicePlanVisibilityRead),
+		},
+
+		Schema: map[string]*schema.Schema{
+			"plan": &schema.Schema{
+				Type:         schema.TypeString,
+				Required:     true,
+				ForceNew:     true,
+				ValidateFunc: validation.NoZeroValues,
+			},
+			"type": &schema.Schema{
+				Type:     schema.TypeString,
+				Required: true,
+				ValidateFunc: validation.StringInSlice([]string{
+					planVisibilityAdmin, planVisibilityPublic, planVisibilityOrganization, planVisibilitySpace,
+				}, false),
+			},
+			"organizations": &schema.Schema{
+				Type:        schema.TypeSet,
+				Optional:    true,
+				Description: "Org GUIDs where plan is visible when type is organization, any other org is removed",
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Set:         schema.HashString,
+			},
+			"space": &schema.Schema{
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "Space GUID where plan is visible when type is space (plan of a space scoped broker)",
+			},
+		},
+
+		CustomizeDiff: func(ctx context.Context, diff *schema.ResourceDiff, meta interface{}) error {
+			if !diff.NewValueKnown("type") || !diff.NewValueKnown("organizations") {
+				return nil
+			}
+			visibilityType := diff.Get("type").(string)
+			orgs := diff.Get("organizations").(*schema.Set)
+			if visibilityType == planVisibilityOrganization && orgs.Len() == 0 {
+				return fmt.Errorf("organizations must contain at least one org when type is %s", planVisibilityOrganization)
+			}
+			if visibilityType != planVisibilityOrganization && orgs.Len() > 0 {
+				return fmt.Errorf("organizations can only be set when type is %s", planVisibilityOrganization)
+			}
+			return nil
+		},
+	}
+}
+
+// v3PlanVisibility - visibility of a service plan as given by cloud controller v3 api
+type v3PlanVisibility struct {
+	Type          string `json:"type"`
+	Organizations []struct {
+		GUID string `json:"guid"`
+	} `json:"organizations,omitempty"`
+	Space *struct {
+		GUID string `json:"guid"`
+	} `json:"space,omitempty"`
+}
+
+func pathPlanVisibility(planGUID string) string {
+	return fmt.Sprintf("/v3/service_plans/%s/visibility", planGUID)
+}
+
+func resourceServicePlanVisibilityRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	session := meta.(*managers.Session)
+
+	var visibility v3PlanVisibility
+	_, err := v3Do(session, "GET", pathPlanVisibility(d.Id()), nil, &visibility)
+	if err != nil {
+		if IsErrNotFound(err) {
+			d.SetId("")
+			return nil
+		}
+		return diag.FromErr(err)
+	}
+	orgs := make([]string, len(visibility.Organizations))
+	for i, o := range visibility.Organizations {
+		orgs[i] = o.GUID
+	}
+	space := ""
+	if visibility.Space != nil {
+		space = visibility.Space.GUID
+	}
+	d.Set("plan", d.Id())
+	d.Set("type", visibility.Type)
+	d.Set("organizations", orgs)
+	d.Set("space", space)
+	return nil
+}
+
+func resourceServicePlanVisibilityUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	session := meta.(*managers.Session)
+	plan := d.Get("plan").(string)
+	visibilityType := d.Get("type").(string)
+
+	// visibility of plan from a space scoped broker is given by its broker and can't be changed
+	if visibilityType == planVisibilitySpace {
+		var current v3PlanVisibility
+		_, err := v3Do(session, "GET", pathPlanVisibility(plan), nil, &current)
+		if err != nil {
+			return diag.FromErr(err)
+		}
+		if current.Type != planVisibilitySpace {
+			return diag.Errorf("Visibility of plan %s is %s, only plans of space scoped brokers have space visibility", plan, current.Type)
+		}
+		d.SetId(plan)
+		return resourceServicePlanVisibilityRead(ctx, d, meta)
+	}
+
+	// PATCH replaces the whole visibility, orgs set outside of terraform or deleted are removed
+	visibility := v3PlanVisibility{Type: visibilityType}
+	for _, o := range d.Get("organizations").(*schema.Set).List() {
+		visibility.Organizations = append(visibility.Organizations, struct {
+			GUID string `json:"guid"`
+		}{GUID: o.(string)})
+	}
+	_, err := v3Do(session, "PATCH", pathPlanVisibility(plan), visibility, nil)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	d.SetId(plan)
+	return resourceServicePlanVisibilityRead(ctx, d, meta)
+}
+
+func resourceServicePlanVisibilityDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	session := meta.(*managers.Session)
+
+	if d.Get("type").(string) == planVisibilitySpace {
+		return nil
+	}
+	// plan goes back to default visibility, only admins can see it
+	_, err := v3Do(session, "PATCH", pathPlanVisibility(d.Id()), v3PlanVisibility{Type: planVisibilityAdmin}, nil)
+	if err != nil && !IsErrNotFound(err) {
+		return diag.FromErr(err)
+	}
+	return nil
+}
@@ -0,0 +1,118 @@
+package cloudfoundry
+
+import (
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+const planVisibilityResource = `
+resource "cloudfoundry_service_broker" "test" {
+	name = "test-visibility"
+	url = "%s"
+	username = "%s"
+	password = "%s"
+}
+
+resource "cloudfoundry_org" "visibility" {
+	name = "test-visibility"
+}
+
+resource "cloudfoundry_service_plan_visibility" "test" {
+	plan = cloudfoundry_service_broker.test.service_plans["%s"]
+	%s
+}
+`
+
+func TestAccResServicePlanVisibility_normal(t *testing.T) {
+
+	serviceBrokerURL, serviceBrokerUser, serviceBrokerPassword, serviceBrokerPlanPath := getTestBrokerCredentials(t)
+
+	deleteServiceBroker("test-visibility")
+
+	orgID, _ := defaultTestOrg(t)
+	ref := "cloudfoundry_service_plan_visibility.test"
+	config := func(visibility string) string {
+		return fmt.Sprintf(planVisibilityResource,
+			serviceBrokerURL, serviceBrokerUser, serviceBrokerPassword, serviceBrokerPlanPath, visibility)
+	}
+
+	resource.Test(t,
+		resource.TestCase{
+			PreCheck:          func() { testAccPreCheck(t) },
+			ProviderFactories: testAccProvidersFactories,
+			CheckDestroy:      testAccCheckServiceBrokerDestroyed("test-visibility"),
+			Steps: []resource.TestStep{
+
+				resource.TestStep{
+					Config: config(fmt.Sprintf(`
+	type = "organization"
+	organizations = [ "%s", cloudfoundry_org.visibility.id ]`, orgID)),
+					Check: resource.ComposeTestCheckFunc(
+						testAccCheckServicePlanVisibility(ref, planVisibilityOrganization, 2),
+						resource.TestCheckResourceAttr(ref, "type", "organization"),
+						resource.TestCheckResourceAttr(ref, "organizations.#", "2"),
+					),
+				},
+
+				resource.TestStep{
+					Config: config(fmt.Sprintf(`
+	type = "organization"
+	organizations = [ "%s" ]`, orgID)),
+					Check: resource.ComposeTestCheckFunc(
+						testAccCheckServicePlanVisibility(ref, planVisibilityOrganization, 1),
+						resource.TestCheckResourceAttr(ref, "organizations.#", "1"),
+					),
+				},
+
+				resource.TestStep{
+					ResourceName:      ref,
+					ImportState:       true,
+					ImportStateVerify: true,
+				},
+
+				resource.TestStep{
+					Config: config(`type = "public"`),
+					Check: resource.ComposeTestCheckFunc(
+						testAccCheckServicePlanVisibility(ref, planVisibilityPublic, 0),
+						resource.TestCheckResourceAttr(ref, "type", "public"),
+						resource.TestCheckResourceAttr(ref, "organizations.#", "0"),
+					),
+				},
+
+				resource.TestStep{
+					Config: config(fmt.Sprintf(`
+	type = "public"
+	organizations = [ "%s" ]`, orgID)),
+					ExpectError: regexp.MustCompile("organizations can only be set when type is organization"),
+				},
+			},
+		})
+}
+
+func testAccCheckServicePlanVisibility(resource string, visibilityType string, orgCount int) resource.TestCheckFunc {
+
+	return func(s *terraform.State) error {
+
+		rs, ok := s.RootModule().Resources[resource]
+		if !ok {
+			return fmt.Errorf("service plan visibility '%s' not found in terraform state", resource)
+		}
+
+		var visibility v3PlanVisibility
+		_, err := v3Do(testSession(), "GET", pathPlanVisibility(rs.Primary.ID), nil, &visibility)
+		if err != nil {
+			return err
+		}
+		if visibility.Type != visibilityType {
+			return fmt.Errorf("expected plan visibility to be %s but is %s", visibilityType, visibility.Type)
+		}
+		if len(visibility.Organizations) != orgCount {
+			return fmt.Errorf("expected plan to be visible in %d orgs but found %d", orgCount, len(visibility.Organizations))
+		}
+		return nil
+	}
+}
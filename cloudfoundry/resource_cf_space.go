@@ -84,8 +84,10 @@ func resourceSpace() *schema.Resource {
 				Elem:       &schema.Schema{Type: schema.TypeString},
 				Set:        resourceStringHash,
 			},
-			labelsKey:      labelsSchema(),
-			annotationsKey: annotationsSchema(),
+			labelsKey:             labelsSchema(),
+			annotationsKey:        annotationsSchema(),
+			"deletion_protection": deletionProtectionSchema(),
+			"delete_recursive":    deleteRecursiveSchema(),
 		},
 	}
 }
@@ -143,6 +145,7 @@ func resourceSpaceRead(ctx context.Context, d *schema.ResourceData, meta interfa
 	d.Set("org", space.OrganizationGUID)
 	d.Set("quota", space.SpaceQuotaDefinitionGUID)
 	d.Set("allow_ssh", space.AllowSSH)
+	deletionProtectionRead(d)
 
 	for t, r := range typeToSpaceRoleMap {
 		users, _, err := sm.GetSpaceUsersByRole(r, id)
@@ -311,13 +314,10 @@ func resourceSpaceUpdate(ctx context.Context, d *schema.ResourceData, meta inter
 
 func resourceSpaceDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	session := meta.(*managers.Session)
-	j, _, err := session.ClientV2.DeleteSpace(d.Id())
-	if err != nil {
-		return diag.FromErr(err)
-	}
-	_, err = session.ClientV2.PollJob(j)
+	err := deletionProtectionCheck(session, d, "space", "space_guids="+d.Id())
 	if err != nil {
 		return diag.FromErr(err)
 	}
+	err = deleteContainer(session, d, "/v2/spaces/"+d.Id())
 	return diag.FromErr(err)
 }
@@ -2,8 +2,12 @@ package cloudfoundry
 
 import (
 	"code.cloudfoundry.org/cli/api/cloudcontroller/ccv2"
+	"context"
 	"fmt"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/terraform-providers/terraform-provider-cloudfoundry/cloudfoundry/managers"
+	"net/http"
+	"regexp"
 	"strconv"
 	"testing"
 
@@ -246,6 +250,61 @@ func TestAccResSpace_normal(t *testing.T) {
 		})
 }
 
+const spaceResourceProtected = `
+resource "cloudfoundry_space" "protected" {
+	name = "space-protected"
+	org = "%s"
+}
+`
+
+const spaceResourceRecursive = `
+resource "cloudfoundry_space" "protected" {
+	name = "space-protected"
+	org = "%s"
+	delete_recursive = true
+}
+`
+
+func TestAccResSpace_deletionProtection(t *testing.T) {
+
+	ref := "cloudfoundry_space.protected"
+	orgID, _ := defaultTestOrg(t)
+
+	resource.Test(t,
+		resource.TestCase{
+			PreCheck:          func() { testAccPreCheck(t) },
+			ProviderFactories: testAccProvidersFactories,
+			CheckDestroy:      testAccCheckSpaceDestroyed("space-protected"),
+			Steps: []resource.TestStep{
+
+				resource.TestStep{
+					Config: fmt.Sprintf(spaceResourceProtected, orgID),
+					Check: resource.ComposeTestCheckFunc(
+						resource.TestCheckResourceAttr(
+							ref, "deletion_protection", "true"),
+						resource.TestCheckResourceAttr(
+							ref, "delete_recursive", "false"),
+						testAccCreateAppInSpace(ref, "app-in-protected-space"),
+					),
+				},
+
+				resource.TestStep{
+					Config:      fmt.Sprintf(spaceResourceProtected, orgID),
+					Destroy:     true,
+					ExpectError: regexp.MustCompile(`(?s)space 'space-protected' is not empty.*apps \(1\): app-in-protected-space`),
+				},
+
+				resource.TestStep{
+					Config: fmt.Sprintf(spaceResourceRecursive, orgID),
+					Check: resource.ComposeTestCheckFunc(
+						resource.TestCheckResourceAttr(
+							ref, "delete_recursive", "true"),
+					),
+				},
+			},
+		})
+}
+
 func testAccCheckSpaceExists(resource string, refUserRemoved *string) resource.TestCheckFunc {
 
 	return func(s *terraform.State) error {
@@ -311,3 +370,92 @@ func testAccCheckSpaceDestroyed(spacename string) resource.TestCheckFunc {
 		return nil
 	}
 }
+
+// testAccCreateAppInSpace - create an app unknown to terraform in space of resource
+func testAccCreateAppInSpace(resource string, name string) resource.TestCheckFunc {
+
+	return func(s *terraform.State) error {
+
+		rs, ok := s.RootModule().Resources[resource]
+		if !ok {
+			return fmt.Errorf("space '%s' not found in terraform state", resource)
+		}
+		app := map[string]interface{}{
+			"name": name,
+			"relationships": map[string]interface{}{
+				"space": newV3Relationship(rs.Primary.ID),
+			},
+		}
+		_, err := v3Do(testSession(), "POST", "/v3/apps", app, nil)
+		return err
+	}
+}
+
+// fakeSpaceCC - in memory cloud controller holding one space with an app, deletion of space
+// is refused unless recursive as done by cloud controller
+type fakeSpaceCC struct {
+	fakeServer
+	deleted bool
+	// recursive flag given on each delete of space
+	deletes []string
+}
+
+func newFakeSpaceCC() *fakeSpaceCC {
+	f := &fakeSpaceCC{}
+	f.start(func(w http.ResponseWriter, r *http.Request, parts []string) {
+		switch {
+		case r.Method == http.MethodDelete && r.URL.Path == "/v2/spaces/space-guid":
+			recursive := r.URL.Query().Get("recursive")
+			f.deletes = append(f.deletes, recursive)
+			if recursive != "true" {
+				f.writeJSON(w, http.StatusBadRequest, map[string]interface{}{
+					"code":        10006,
+					"description": "Please delete the app associations for your spaces.",
+					"error_code":  "CF-AssociationNotEmpty",
+				})
+				return
+			}
+			f.deleted = true
+			f.writeJSON(w, http.StatusAccepted, map[string]interface{}{
+				"metadata": map[string]interface{}{"guid": "job-guid"},
+				"entity":   map[string]interface{}{"guid": "job-guid", "status": "queued"},
+			})
+		case r.Method == http.MethodGet && r.URL.Path == "/v2/jobs/job-guid":
+			f.writeJSON(w, http.StatusOK, map[string]interface{}{
+				"metadata": map[string]interface{}{"guid": "job-guid"},
+				"entity":   map[string]interface{}{"guid": "job-guid", "status": "finished"},
+			})
+		default:
+			f.writeJSON(w, http.StatusNotFound, nil)
+		}
+	})
+	return f
+}
+
+func TestSpaceDelete_recursiveOnlyWhenSet(t *testing.T) {
+	for _, recursive := range []bool{false, true} {
+		t.Run(strconv.FormatBool(recursive), func(t *testing.T) {
+			cc := newFakeSpaceCC()
+			defer cc.server.Close()
+
+			r := resourceSpace()
+			d := schema.TestResourceDataRaw(t, r.Schema, map[string]interface{}{
+				"name":                "fake-space",
+				"org":                 "org-guid",
+				"deletion_protection": false,
+				"delete_recursive":    recursive,
+			})
+			d.SetId("space-guid")
+			diags := r.DeleteContext(context.Background(), d, cc.session())
+			if diags.HasError() == recursive {
+				t.Fatalf("expected delete to fail only when not recursive but found %v", diags)
+			}
+			if len(cc.deletes) != 1 || cc.deletes[0] != strconv.FormatBool(recursive) {
+				t.Fatalf("expected one delete with recursive=%t but found %v", recursive, cc.deletes)
+			}
+			if cc.deleted != recursive {
+				t.Fatalf("expected space to be deleted only when recursive")
+			}
+		})
+	}
+}
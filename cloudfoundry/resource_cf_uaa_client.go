@@ -0,0 +1,325 @@
+package cloudfoundry
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"github.com/terraform-providers/terraform-provider-cloudfoundry/cloudfoundry/managers"
+)
+
+const (
+	uaaSecretChangeAdd    = "ADD"
+	uaaSecretChangeDelete = "DELETE"
+)
+
+var uaaGrantTypes = []string{
+	"authorization_code",
+	"implicit",
+	"password",
+	"client_credentials",
+	"refresh_token",
+	"user_token",
+	"urn:ietf:params:oauth:grant-type:saml2-bearer",
+	"urn:ietf:params:oauth:grant-type:jwt-bearer",
+}
+
+func resourceUAAClient() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceUAAClientCreate,
+		ReadContext:   resourceUAAClientRead,
+		UpdateContext: resourceUAAClientUpdate,
+		DeleteContext: resourceUAAClientDelete,
+
+		Importer: &schema.ResourceImporter{
+			StateContext: ImportReadContext(resourceUAAClientRead),
+		},
+
+		Schema: map[string]*schema.Schema{
+			"client_id": &schema.Schema{
+				Type:         schema.TypeString,
+				Required:     true,
+				ForceNew:     true,
+				ValidateFunc: validation.NoZeroValues,
+			},
+			"client_secret": &schema.Schema{
+				Type:      schema.TypeString,
+				Optional:  true,
+				Sensitive: true,
+			},
+			"keep_previous_secret": &schema.Schema{
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "Keep previous secret active when client_secret changes, set back to false to revoke it",
+			},
+			"previous_secret_active": &schema.Schema{
+				Type:     schema.TypeBool,
+				Computed: true,
+			},
+			"name": &schema.Schema{
+				Type:     schema.TypeString,
+				Optional: true,
+				Computed: true,
+			},
+			"authorized_grant_types": &schema.Schema{
+				Type:     schema.TypeSet,
+				Required: true,
+				Elem: &schema.Schema{
+					Type:         schema.TypeString,
+					ValidateFunc: validation.StringInSlice(uaaGrantTypes, false),
+				},
+				Set: schema.HashString,
+			},
+			"scope": &schema.Schema{
+				Type:     schema.TypeSet,
+				Optional: true,
+				Computed: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+				Set:      schema.HashString,
+			},
+			"authorities": &schema.Schema{
+				Type:     schema.TypeSet,
+				Optional: true,
+				Computed: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+				Set:      schema.HashString,
+			},
+			"redirect_uri": &schema.Schema{
+				Type:     schema.TypeSet,
+				Optional: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+				Set:      schema.HashString,
+			},
+			"auto_approve": &schema.Schema{
+				Type:        schema.TypeSet,
+				Optional:    true,
+				Description: "Scopes approved without asking user, true approves all scopes",
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Set:         schema.HashString,
+			},
+			"access_token_validity": &schema.Schema{
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Computed:     true,
+				Description:  "Validity of access tokens in seconds",
+				ValidateFunc: validation.IntAtLeast(0),
+			},
+			"refresh_token_validity": &schema.Schema{
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Computed:     true,
+				Description:  "Validity of refresh tokens in seconds",
+				ValidateFunc: validation.IntAtLeast(0),
+			},
+		},
+
+		CustomizeDiff: func(ctx context.Context, diff *schema.ResourceDiff, meta interface{}) error {
+			if !diff.NewValueKnown("authorized_grant_types") || !diff.NewValueKnown("client_secret") || !diff.NewValueKnown("redirect_uri") {
+				return nil
+			}
+			grantTypes := diff.Get("authorized_grant_types").(*schema.Set)
+			hasSecret := diff.Get("client_secret").(string) != ""
+			hasRedirect := diff.Get("redirect_uri").(*schema.Set).Len() > 0
+			if grantTypes.Contains("implicit") && hasSecret {
+				return fmt.Errorf("client_secret can't be set with implicit grant type")
+			}
+			if grantTypes.Contains("client_credentials") && !hasSecret {
+				return fmt.Errorf("client_secret is required with client_credentials grant type")
+			}
+			if (grantTypes.Contains("authorization_code") || grantTypes.Contains("implicit")) && !hasRedirect {
+				return fmt.Errorf("redirect_uri is required with authorization_code and implicit grant types")
+			}
+			if diff.Id() != "" && (diff.HasChange("client_secret") || diff.HasChange("keep_previous_secret")) {
+				return diff.SetNewComputed("previous_secret_active")
+			}
+			return nil
+		},
+	}
+}
+
+// uaaClient - oauth client as given by uaa api, secret is never given back
+type uaaClient struct {
+	ClientID             string         `json:"client_id"`
+	ClientSecret         string         `json:"client_secret,omitempty"`
+	Name                 string         `json:"name,omitempty"`
+	AuthorizedGrantTypes []string       `json:"authorized_grant_types"`
+	Scope                []string       `json:"scope,omitempty"`
+	Authorities          []string       `json:"authorities,omitempty"`
+	RedirectURI          []string       `json:"redirect_uri,omitempty"`
+	AutoApprove          uaaAutoApprove `json:"autoapprove,omitempty"`
+	AccessTokenValidity  int            `json:"access_token_validity,omitempty"`
+	RefreshTokenValidity int            `json:"refresh_token_validity,omitempty"`
+}
+
+// uaaAutoApprove - scopes approved without asking user, uaa gives true instead of a list when all scopes are approved
+type uaaAutoApprove []string
+
+func (a uaaAutoApprove) MarshalJSON() ([]byte, error) {
+	if len(a) == 1 && a[0] == "true" {
+		return []byte("true"), nil
+	}
+	return json.Marshal([]string(a))
+}
+
+func (a *uaaAutoApprove) UnmarshalJSON(b []byte) error {
+	var all bool
+	if err := json.Unmarshal(b, &all); err == nil {
+		*a = nil
+		if all {
+			*a = uaaAutoApprove{"true"}
+		}
+		return nil
+	}
+	var scopes []string
+	if err := json.Unmarshal(b, &scopes); err != nil {
+		return err
+	}
+	*a = scopes
+	return nil
+}
+
+func resourceDataToUAAClient(d *schema.ResourceData) uaaClient {
+	setToSlice := func(key string) []string {
+		list := d.Get(key).(*schema.Set).List()
+		values := make([]string, len(list))
+		for i, v := range list {
+			values[i] = v.(string)
+		}
+		return values
+	}
+	return uaaClient{
+		ClientID:             d.Get("client_id").(string),
+		Name:                 d.Get("name").(string),
+		AuthorizedGrantTypes: setToSlice("authorized_grant_types"),
+		Scope:                setToSlice("scope"),
+		Authorities:          setToSlice("authorities"),
+		RedirectURI:          setToSlice("redirect_uri"),
+		AutoApprove:          uaaAutoApprove(setToSlice("auto_approve")),
+		AccessTokenValidity:  d.Get("access_token_validity").(int),
+		RefreshTokenValidity: d.Get("refresh_token_validity").(int),
+	}
+}
+
+func resourceUAAClientCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	session := meta.(*managers.Session)
+
+	client := resourceDataToUAAClient(d)
+	client.ClientSecret = d.Get("client_secret").(string)
+	err := uaaDo(session, "POST", "/oauth/clients", client, nil)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	d.SetId(client.ClientID)
+	d.Set("previous_secret_active", false)
+	return resourceUAAClientRead(ctx, d, meta)
+}
+
+func resourceUAAClientRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	session := meta.(*managers.Session)
+
+	var client uaaClient
+	err := uaaDo(session, "GET", fmt.Sprintf("/oauth/clients/%s", d.Id()), nil, &client)
+	if err != nil {
+		if IsErrNotFound(err) {
+			d.SetId("")
+			return nil
+		}
+		return diag.FromErr(err)
+	}
+	d.Set("client_id", client.ClientID)
+	d.Set("name", client.Name)
+	d.Set("authorized_grant_types", client.AuthorizedGrantTypes)
+	d.Set("scope", client.Scope)
+	d.Set("authorities", client.Authorities)
+	d.Set("redirect_uri", client.RedirectURI)
+	d.Set("auto_approve", []string(client.AutoApprove))
+	d.Set("access_token_validity", client.AccessTokenValidity)
+	d.Set("refresh_token_validity", client.RefreshTokenValidity)
+	if _, ok := d.GetOk("previous_secret_active"); !ok {
+		d.Set("previous_secret_active", false)
+	}
+	return nil
+}
+
+func resourceUAAClientUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	session := meta.(*managers.Session)
+
+	// state is left as before when client can't be updated
+	d.Partial(true)
+	// secret is not part of client update, it is changed with its own endpoint
+	if d.HasChange("name") || d.HasChange("authorized_grant_types") || d.HasChange("scope") ||
+		d.HasChange("authorities") || d.HasChange("redirect_uri") || d.HasChange("auto_approve") ||
+		d.HasChange("access_token_validity") || d.HasChange("refresh_token_validity") {
+		err := uaaDo(session, "PUT", fmt.Sprintf("/oauth/clients/%s", d.Id()), resourceDataToUAAClient(d), nil)
+		if err != nil {
+			return diag.FromErr(err)
+		}
+	}
+	d.Partial(false)
+
+	err := updateUAAClientSecret(session, d)
+	if err != nil {
+		// secret change is retried on next apply, previous_secret_active is kept as set by updateUAAClientSecret
+		for _, k := range []string{"client_secret", "keep_previous_secret"} {
+			old, _ := d.GetChange(k)
+			d.Set(k, old)
+		}
+		return diag.FromErr(err)
+	}
+	return resourceUAAClientRead(ctx, d, meta)
+}
+
+// updateUAAClientSecret - change secret of client, uaa keeps at most two active secrets.
+// When previous secret is kept, older one is revoked first to make room for the new one.
+// previous_secret_active is always set as it is in uaa, even when changing secret fails
+func updateUAAClientSecret(session *managers.Session, d *schema.ResourceData) error {
+	keepPrevious := d.Get("keep_previous_secret").(bool)
+	// new value is unknown until secret is changed
+	old, _ := d.GetChange("previous_secret_active")
+	previousActive := old.(bool)
+	d.Set("previous_secret_active", previousActive)
+	path := fmt.Sprintf("/oauth/clients/%s/secret", d.Id())
+
+	if previousActive && (!keepPrevious || d.HasChange("client_secret")) {
+		err := uaaDo(session, "PUT", path, map[string]string{
+			"clientId":   d.Id(),
+			"changeMode": uaaSecretChangeDelete,
+		}, nil)
+		if err != nil {
+			return err
+		}
+		previousActive = false
+		d.Set("previous_secret_active", previousActive)
+	}
+
+	if d.HasChange("client_secret") {
+		change := map[string]string{
+			"clientId": d.Id(),
+			"secret":   d.Get("client_secret").(string),
+		}
+		if keepPrevious {
+			change["changeMode"] = uaaSecretChangeAdd
+		}
+		err := uaaDo(session, "PUT", path, change, nil)
+		if err != nil {
+			return err
+		}
+		previousActive = keepPrevious
+	}
+	d.Set("previous_secret_active", previousActive)
+	return nil
+}
+
+func resourceUAAClientDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	session := meta.(*managers.Session)
+
+	err := uaaDo(session, "DELETE", fmt.Sprintf("/oauth/clients/%s", d.Id()), nil, nil)
+	if err != nil && !IsErrNotFound(err) {
+		return diag.FromErr(err)
+	}
+	return nil
+}
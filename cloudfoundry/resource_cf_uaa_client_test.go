@@ -0,0 +1,294 @@
+package cloudfoundry
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+const uaaClientResource = `
+resource "cloudfoundry_uaa_client" "ci" {
+  client_id = "ci-pipeline"
+  client_secret = "%s"
+  keep_previous_secret = %t
+  authorized_grant_types = ["client_credentials"]
+  authorities = ["cloud_controller.read", "cloud_controller.write"]
+  access_token_validity = %d
+}
+`
+
+const uaaClientResourceWeb = `
+resource "cloudfoundry_uaa_client" "web" {
+  client_id = "web-app"
+  client_secret = "web-secret"
+  name = "Web app"
+  authorized_grant_types = ["authorization_code", "refresh_token"]
+  scope = ["openid", "cloud_controller.read"]
+  redirect_uri = ["https://web-app.example.com/callback"]
+  auto_approve = ["openid"]
+  refresh_token_validity = 86400
+}
+`
+
+const uaaClientResourceInvalid = `
+resource "cloudfoundry_uaa_client" "invalid" {
+  client_id = "invalid"
+  authorized_grant_types = ["client_credentials"]
+}
+`
+
+func TestAccResUAAClient_normal(t *testing.T) {
+	ref := "cloudfoundry_uaa_client.ci"
+
+	resource.Test(t,
+		resource.TestCase{
+			PreCheck:          func() { testAccPreCheck(t) },
+			ProviderFactories: testAccProvidersFactories,
+			CheckDestroy:      testAccCheckUAAClientDestroyed(ref),
+			Steps: []resource.TestStep{
+
+				resource.TestStep{
+					Config: fmt.Sprintf(uaaClientResource, "secret-1", false, 600),
+					Check: resource.ComposeTestCheckFunc(
+						testAccCheckUAAClientSecret(ref, "secret-1", true),
+						resource.TestCheckResourceAttr(ref, "authorities.#", "2"),
+						resource.TestCheckResourceAttr(ref, "access_token_validity", "600"),
+						resource.TestCheckResourceAttr(ref, "previous_secret_active", "false"),
+					),
+				},
+
+				// rotation: both secrets are active
+				resource.TestStep{
+					Config: fmt.Sprintf(uaaClientResource, "secret-2", true, 1200),
+					Check: resource.ComposeTestCheckFunc(
+						testAccCheckUAAClientSecret(ref, "secret-1", true),
+						testAccCheckUAAClientSecret(ref, "secret-2", true),
+						resource.TestCheckResourceAttr(ref, "access_token_validity", "1200"),
+						resource.TestCheckResourceAttr(ref, "previous_secret_active", "true"),
+					),
+				},
+
+				// next rotation revokes oldest secret
+				resource.TestStep{
+					Config: fmt.Sprintf(uaaClientResource, "secret-3", true, 1200),
+					Check: resource.ComposeTestCheckFunc(
+						testAccCheckUAAClientSecret(ref, "secret-1", false),
+						testAccCheckUAAClientSecret(ref, "secret-2", true),
+						testAccCheckUAAClientSecret(ref, "secret-3", true),
+						resource.TestCheckResourceAttr(ref, "previous_secret_active", "true"),
+					),
+				},
+
+				// end of rotation revokes previous secret
+				resource.TestStep{
+					Config: fmt.Sprintf(uaaClientResource, "secret-3", false, 1200),
+					Check: resource.ComposeTestCheckFunc(
+						testAccCheckUAAClientSecret(ref, "secret-2", false),
+						testAccCheckUAAClientSecret(ref, "secret-3", true),
+						resource.TestCheckResourceAttr(ref, "previous_secret_active", "false"),
+					),
+				},
+
+				resource.TestStep{
+					ResourceName:            ref,
+					ImportState:             true,
+					ImportStateVerify:       true,
+					ImportStateVerifyIgnore: []string{"client_secret", "keep_previous_secret"},
+				},
+			},
+		})
+}
+
+func TestAccResUAAClient_authorizationCode(t *testing.T) {
+	ref := "cloudfoundry_uaa_client.web"
+
+	resource.Test(t,
+		resource.TestCase{
+			PreCheck:          func() { testAccPreCheck(t) },
+			ProviderFactories: testAccProvidersFactories,
+			CheckDestroy:      testAccCheckUAAClientDestroyed(ref),
+			Steps: []resource.TestStep{
+
+				resource.TestStep{
+					Config: uaaClientResourceWeb,
+					Check: resource.ComposeTestCheckFunc(
+						resource.TestCheckResourceAttr(ref, "name", "Web app"),
+						resource.TestCheckResourceAttr(ref, "authorized_grant_types.#", "2"),
+						resource.TestCheckResourceAttr(ref, "scope.#", "2"),
+						resource.TestCheckResourceAttr(ref, "redirect_uri.#", "1"),
+						resource.TestCheckResourceAttr(ref, "auto_approve.#", "1"),
+						resource.TestCheckResourceAttr(ref, "refresh_token_validity", "86400"),
+					),
+				},
+			},
+		})
+}
+
+func TestAccResUAAClient_invalid(t *testing.T) {
+	resource.Test(t,
+		resource.TestCase{
+			PreCheck:          func() { testAccPreCheck(t) },
+			ProviderFactories: testAccProvidersFactories,
+			Steps: []resource.TestStep{
+
+				resource.TestStep{
+					Config:      uaaClientResourceInvalid,
+					PlanOnly:    true,
+					ExpectError: regexp.MustCompile("client_secret is required with client_credentials grant type"),
+				},
+			},
+		})
+}
+
+// testAccCheckUAAClientSecret - check that a token can be obtained, or not, with given secret
+func testAccCheckUAAClientSecret(resource, secret string, valid bool) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources[resource]
+		if !ok {
+			return fmt.Errorf("uaa client '%s' not found in terraform state", resource)
+		}
+		session := testSession()
+		resp, err := session.HttpClient.PostForm(session.ClientUAA.UAALink()+"/oauth/token", map[string][]string{
+			"grant_type":    {"client_credentials"},
+			"client_id":     {rs.Primary.ID},
+			"client_secret": {secret},
+		})
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+		if valid && resp.StatusCode != 200 {
+			return fmt.Errorf("secret '%s' of uaa client '%s' should be active but token request failed with %d", secret, rs.Primary.ID, resp.StatusCode)
+		}
+		if !valid && resp.StatusCode == 200 {
+			return fmt.Errorf("secret '%s' of uaa client '%s' should have been revoked", secret, rs.Primary.ID)
+		}
+		return nil
+	}
+}
+
+func testAccCheckUAAClientDestroyed(resource string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources[resource]
+		if !ok {
+			return nil
+		}
+		err := uaaDo(testSession(), "GET", fmt.Sprintf("/oauth/clients/%s", rs.Primary.ID), nil, nil)
+		if err == nil {
+			return fmt.Errorf("uaa client %s still exists", rs.Primary.ID)
+		}
+		if !IsErrNotFound(err) {
+			return err
+		}
+		return nil
+	}
+}
+
+func TestUAAClient_autoApproveAll(t *testing.T) {
+	uaa := newFakeUAA()
+	defer uaa.server.Close()
+	session := uaa.session()
+	ctx := context.Background()
+
+	r := resourceUAAClient()
+	d := schema.TestResourceDataRaw(t, r.Schema, map[string]interface{}{
+		"client_id":              "web-app",
+		"client_secret":          "web-secret",
+		"authorized_grant_types": []interface{}{"authorization_code"},
+		"scope":                  []interface{}{"openid", "cloud_controller.read"},
+		"auto_approve":           []interface{}{"true"},
+	})
+	if diags := r.CreateContext(ctx, d, session); diags.HasError() {
+		t.Fatalf("create failed: %v", diags)
+	}
+	// uaa gives back true when all scopes are approved
+	if v := uaa.clients["web-app"]["autoapprove"]; v != true {
+		t.Fatalf("expected autoapprove to be sent as true but found %v", v)
+	}
+
+	for _, c := range []struct {
+		autoApprove interface{}
+		expected    []string
+	}{
+		{true, []string{"true"}},
+		{[]interface{}{"openid"}, []string{"openid"}},
+		{false, []string{}},
+	} {
+		uaa.clients["web-app"]["autoapprove"] = c.autoApprove
+		if diags := r.ReadContext(ctx, d, session); diags.HasError() {
+			t.Fatalf("read with autoapprove %v failed: %v", c.autoApprove, diags)
+		}
+		found := d.Get("auto_approve").(*schema.Set)
+		if found.Len() != len(c.expected) {
+			t.Fatalf("expected auto_approve %v for autoapprove %v but found %v", c.expected, c.autoApprove, found.List())
+		}
+		for _, e := range c.expected {
+			if !found.Contains(e) {
+				t.Fatalf("expected auto_approve %v for autoapprove %v but found %v", c.expected, c.autoApprove, found.List())
+			}
+		}
+	}
+}
+
+func TestUAAClientUpdate_failureKeepsSecret(t *testing.T) {
+	for _, failure := range []string{"client", "secret"} {
+		t.Run(failure, func(t *testing.T) {
+			uaa := newFakeUAA()
+			defer uaa.server.Close()
+			session := uaa.session()
+			ctx := context.Background()
+
+			r := resourceUAAClient()
+			d := schema.TestResourceDataRaw(t, r.Schema, map[string]interface{}{
+				"client_id":              "ci-pipeline",
+				"client_secret":          "secret-1",
+				"authorized_grant_types": []interface{}{"client_credentials"},
+				"authorities":            []interface{}{"cloud_controller.read"},
+			})
+			if diags := r.CreateContext(ctx, d, session); diags.HasError() {
+				t.Fatalf("create failed: %v", diags)
+			}
+			state := d.State()
+			diff, err := r.Diff(ctx, state, terraform.NewResourceConfigRaw(map[string]interface{}{
+				"client_id":              "ci-pipeline",
+				"client_secret":          "secret-2",
+				"keep_previous_secret":   true,
+				"authorized_grant_types": []interface{}{"client_credentials"},
+				"authorities":            []interface{}{"cloud_controller.read", "cloud_controller.write"},
+			}), session)
+			if err != nil {
+				t.Fatalf("diff failed: %s", err)
+			}
+
+			// authorities are kept as before only when client itself can't be updated
+			authorities := "2"
+			if failure == "client" {
+				delete(uaa.clients, "ci-pipeline")
+				authorities = "1"
+			} else {
+				uaa.secretStatus = http.StatusBadGateway
+			}
+			newState, diags := r.Apply(ctx, state, diff, session)
+			if !diags.HasError() {
+				t.Fatalf("expected update to fail")
+			}
+			// secret has not been changed, next plan must show the change again
+			for k, v := range map[string]string{
+				"client_secret":          "secret-1",
+				"keep_previous_secret":   "false",
+				"previous_secret_active": "false",
+				"authorities.#":          authorities,
+			} {
+				if newState.Attributes[k] != v {
+					t.Fatalf("expected %s to be '%s' but found '%s'", k, v, newState.Attributes[k])
+				}
+			}
+		})
+	}
+}
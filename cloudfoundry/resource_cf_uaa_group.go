@@ -0,0 +1,209 @@
+package cloudfoundry
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"github.com/terraform-providers/terraform-provider-cloudfoundry/cloudfoundry/managers"
+)
+
+func resourceUAAGroup() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceUAAGroupCreate,
+		ReadContext:   resourceUAAGroupRead,
+		UpdateContext: resourceUAAGroupUpdate,
+		DeleteContext: resourceUAAGroupDelete,
+
+		Importer: &schema.ResourceImporter{
+			StateContext: ImportReadContext(resourceUAAGroupRead),
+		},
+
+		Schema: map[string]*schema.Schema{
+			"display_name": &schema.Schema{
+				Type:         schema.TypeString,
+				Required:     true,
+				Description:  "Name of the group, it is the scope given to its members (e.g. network.write)",
+				ValidateFunc: validation.NoZeroValues,
+			},
+			"description": &schema.Schema{
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+			"external_group": &schema.Schema{
+				Type:        schema.TypeSet,
+				Optional:    true,
+				Description: "Groups of an external identity provider mapped to this group, any other mapping is removed",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"external_group": &schema.Schema{
+							Type:         schema.TypeString,
+							Required:     true,
+							Description:  "Group in identity provider, a DN for ldap or a group name for saml",
+							ValidateFunc: validation.NoZeroValues,
+						},
+						"origin": &schema.Schema{
+							Type:     schema.TypeString,
+							Optional: true,
+							Default:  "ldap",
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// uaaGroup - group as given by uaa scim api
+type uaaGroup struct {
+	ID          string `json:"id,omitempty"`
+	DisplayName string `json:"displayName"`
+	Description string `json:"description,omitempty"`
+}
+
+// uaaGroupMapping - mapping between a group of an external identity provider and a uaa group
+type uaaGroupMapping struct {
+	GroupID       string `json:"groupId"`
+	ExternalGroup string `json:"externalGroup"`
+	Origin        string `json:"origin"`
+}
+
+func getUAAGroupMappings(session *managers.Session, groupID string) ([]uaaGroupMapping, error) {
+	mappings := make([]uaaGroupMapping, 0)
+	query := url.Values{"filter": []string{fmt.Sprintf(`groupId eq "%s"`, groupID)}}
+	err := uaaGetAll(session, "/Groups/External", query, func(resources json.RawMessage) error {
+		var page []uaaGroupMapping
+		err := json.Unmarshal(resources, &page)
+		if err != nil {
+			return err
+		}
+		mappings = append(mappings, page...)
+		return nil
+	})
+	return mappings, err
+}
+
+func resourceUAAGroupCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	session := meta.(*managers.Session)
+
+	var group uaaGroup
+	err := uaaDo(session, "POST", "/Groups", uaaGroup{
+		DisplayName: d.Get("display_name").(string),
+		Description: d.Get("description").(string),
+	}, &group)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	d.SetId(group.ID)
+
+	err = updateUAAGroupMappings(session, d)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	return resourceUAAGroupRead(ctx, d, meta)
+}
+
+func resourceUAAGroupRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	session := meta.(*managers.Session)
+
+	var group uaaGroup
+	err := uaaDo(session, "GET", fmt.Sprintf("/Groups/%s", d.Id()), nil, &group)
+	if err != nil {
+		if IsErrNotFound(err) {
+			d.SetId("")
+			return nil
+		}
+		return diag.FromErr(err)
+	}
+	d.Set("display_name", group.DisplayName)
+	d.Set("description", group.Description)
+
+	mappings, err := getUAAGroupMappings(session, d.Id())
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	externalGroups := make([]map[string]interface{}, len(mappings))
+	for i, m := range mappings {
+		externalGroups[i] = map[string]interface{}{
+			"external_group": m.ExternalGroup,
+			"origin":         m.Origin,
+		}
+	}
+	d.Set("external_group", externalGroups)
+	return nil
+}
+
+func resourceUAAGroupUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	session := meta.(*managers.Session)
+
+	if d.HasChange("display_name") || d.HasChange("description") {
+		// put replaces whole group, current group is sent back to not lose its members
+		var group map[string]interface{}
+		err := uaaDo(session, "GET", fmt.Sprintf("/Groups/%s", d.Id()), nil, &group)
+		if err != nil {
+			return diag.FromErr(err)
+		}
+		group["displayName"] = d.Get("display_name").(string)
+		group["description"] = d.Get("description").(string)
+		err = uaaDo(session, "PUT", fmt.Sprintf("/Groups/%s", d.Id()), group, nil)
+		if err != nil {
+			return diag.FromErr(err)
+		}
+	}
+	err := updateUAAGroupMappings(session, d)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	return resourceUAAGroupRead(ctx, d, meta)
+}
+
+// updateUAAGroupMappings - make external group mappings of group match the ones in resource
+func updateUAAGroupMappings(session *managers.Session, d *schema.ResourceData) error {
+	current, err := getUAAGroupMappings(session, d.Id())
+	if err != nil {
+		return err
+	}
+	wanted := make(map[uaaGroupMapping]bool)
+	for _, e := range d.Get("external_group").(*schema.Set).List() {
+		e := e.(map[string]interface{})
+		wanted[uaaGroupMapping{
+			GroupID:       d.Id(),
+			ExternalGroup: e["external_group"].(string),
+			Origin:        e["origin"].(string),
+		}] = true
+	}
+	for _, m := range current {
+		if wanted[m] {
+			delete(wanted, m)
+			continue
+		}
+		path := fmt.Sprintf("/Groups/External/groupId/%s/externalGroup/%s/origin/%s",
+			m.GroupID, url.PathEscape(m.ExternalGroup), url.PathEscape(m.Origin))
+		err := uaaDo(session, "DELETE", path, nil, nil)
+		if err != nil && !IsErrNotFound(err) {
+			return err
+		}
+	}
+	for m := range wanted {
+		err := uaaDo(session, "POST", "/Groups/External", m, nil)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func resourceUAAGroupDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	session := meta.(*managers.Session)
+
+	// mappings and memberships of group are removed with it by uaa
+	err := uaaDo(session, "DELETE", fmt.Sprintf("/Groups/%s", d.Id()), nil, nil)
+	if err != nil && !IsErrNotFound(err) {
+		return diag.FromErr(err)
+	}
+	return nil
+}
@@ -0,0 +1,148 @@
+package cloudfoundry
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/terraform-providers/terraform-provider-cloudfoundry/cloudfoundry/managers"
+)
+
+const (
+	uaaMemberUser  = "USER"
+	uaaMemberGroup = "GROUP"
+)
+
+func resourceUAAGroupMembership() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceUAAGroupMembershipUpdate,
+		ReadContext:   resourceUAAGroupMembershipRead,
+		UpdateContext: resourceUAAGroupMembershipUpdate,
+		DeleteContext: resourceUAAGroupMembershipDelete,
+
+		Importer: &schema.ResourceImporter{
+			StateContext: ImportReadContext(resourceUAAGroupMembershipRead),
+		},
+
+		Schema: map[string]*schema.Schema{
+			"group": &schema.Schema{
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+			"users": &schema.Schema{
+				Type:        schema.TypeSet,
+				Optional:    true,
+				Description: "User GUIDs members of the group, any other user is removed",
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Set:         schema.HashString,
+			},
+			"groups": &schema.Schema{
+				Type:        schema.TypeSet,
+				Optional:    true,
+				Description: "Group GUIDs members of the group, any other group is removed",
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Set:         schema.HashString,
+			},
+		},
+	}
+}
+
+// uaaGroupMember - member of a uaa group, a user or another group
+type uaaGroupMember struct {
+	Value  string `json:"value"`
+	Type   string `json:"type"`
+	Origin string `json:"origin,omitempty"`
+}
+
+func getUAAGroupMembers(session *managers.Session, groupID string) ([]uaaGroupMember, error) {
+	var members []uaaGroupMember
+	err := uaaDo(session, "GET", fmt.Sprintf("/Groups/%s/members", groupID), nil, &members)
+	return members, err
+}
+
+func resourceUAAGroupMembershipRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	session := meta.(*managers.Session)
+
+	members, err := getUAAGroupMembers(session, d.Id())
+	if err != nil {
+		if IsErrNotFound(err) {
+			d.SetId("")
+			return nil
+		}
+		return diag.FromErr(err)
+	}
+	users := make([]string, 0)
+	groups := make([]string, 0)
+	for _, m := range members {
+		if m.Type == uaaMemberGroup {
+			groups = append(groups, m.Value)
+		} else {
+			users = append(users, m.Value)
+		}
+	}
+	d.Set("group", d.Id())
+	d.Set("users", users)
+	d.Set("groups", groups)
+	return nil
+}
+
+func resourceUAAGroupMembershipUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	session := meta.(*managers.Session)
+	groupID := d.Get("group").(string)
+
+	members, err := getUAAGroupMembers(session, groupID)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	wanted := make(map[string]string)
+	for _, u := range d.Get("users").(*schema.Set).List() {
+		wanted[u.(string)] = uaaMemberUser
+	}
+	for _, g := range d.Get("groups").(*schema.Set).List() {
+		wanted[g.(string)] = uaaMemberGroup
+	}
+
+	// members added outside of terraform are removed too, membership is authoritative
+	for _, m := range members {
+		if t, ok := wanted[m.Value]; ok && t == m.Type {
+			delete(wanted, m.Value)
+			continue
+		}
+		err := uaaDo(session, "DELETE", fmt.Sprintf("/Groups/%s/members/%s", groupID, m.Value), nil, nil)
+		if err != nil && !IsErrNotFound(err) {
+			return diag.FromErr(err)
+		}
+	}
+	for value, t := range wanted {
+		err := uaaDo(session, "POST", fmt.Sprintf("/Groups/%s/members", groupID), uaaGroupMember{
+			Value: value,
+			Type:  t,
+		}, nil)
+		if err != nil {
+			return diag.FromErr(err)
+		}
+	}
+	d.SetId(groupID)
+	return resourceUAAGroupMembershipRead(ctx, d, meta)
+}
+
+func resourceUAAGroupMembershipDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	session := meta.(*managers.Session)
+
+	members, err := getUAAGroupMembers(session, d.Id())
+	if err != nil {
+		if IsErrNotFound(err) {
+			return nil
+		}
+		return diag.FromErr(err)
+	}
+	for _, m := range members {
+		err := uaaDo(session, "DELETE", fmt.Sprintf("/Groups/%s/members/%s", d.Id(), m.Value), nil, nil)
+		if err != nil && !IsErrNotFound(err) {
+			return diag.FromErr(err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,209 @@
+package cloudfoundry
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+const uaaGroupResource = `
+resource "cloudfoundry_uaa_group" "custom" {
+  display_name = "custom.read"
+  description = "Read access on custom api"
+}
+`
+
+const uaaGroupResourceUpdate = `
+resource "cloudfoundry_uaa_group" "custom" {
+  display_name = "custom.write"
+  description = "Write access on custom api"
+
+  external_group {
+    external_group = "cn=developers,ou=groups,dc=example,dc=org"
+  }
+  external_group {
+    external_group = "custom-writers"
+    origin = "saml"
+  }
+}
+`
+
+const uaaGroupMembershipResource = `
+resource "cloudfoundry_user" "member" {
+  name = "uaa-group-member@acme.com"
+  password = "password"
+}
+
+resource "cloudfoundry_uaa_group" "custom" {
+  display_name = "custom.admin"
+}
+
+resource "cloudfoundry_uaa_group" "nested" {
+  display_name = "custom.nested"
+}
+
+resource "cloudfoundry_uaa_group_membership" "custom" {
+  group = cloudfoundry_uaa_group.custom.id
+  users = [cloudfoundry_user.member.id]
+  groups = [cloudfoundry_uaa_group.nested.id]
+}
+`
+
+const uaaGroupMembershipResourceUpdate = `
+resource "cloudfoundry_user" "member" {
+  name = "uaa-group-member@acme.com"
+  password = "password"
+}
+
+resource "cloudfoundry_uaa_group" "custom" {
+  display_name = "custom.admin"
+}
+
+resource "cloudfoundry_uaa_group" "nested" {
+  display_name = "custom.nested"
+}
+
+resource "cloudfoundry_uaa_group_membership" "custom" {
+  group = cloudfoundry_uaa_group.custom.id
+  users = [cloudfoundry_user.member.id]
+}
+`
+
+func TestAccResUAAGroup_normal(t *testing.T) {
+	ref := "cloudfoundry_uaa_group.custom"
+
+	resource.Test(t,
+		resource.TestCase{
+			PreCheck:          func() { testAccPreCheck(t) },
+			ProviderFactories: testAccProvidersFactories,
+			CheckDestroy:      testAccCheckUAAGroupDestroyed(ref),
+			Steps: []resource.TestStep{
+
+				resource.TestStep{
+					Config: uaaGroupResource,
+					Check: resource.ComposeTestCheckFunc(
+						testAccCheckUAAGroupExists(ref),
+						resource.TestCheckResourceAttr(ref, "display_name", "custom.read"),
+						resource.TestCheckResourceAttr(ref, "description", "Read access on custom api"),
+						resource.TestCheckResourceAttr(ref, "external_group.#", "0"),
+					),
+				},
+
+				resource.TestStep{
+					Config: uaaGroupResourceUpdate,
+					Check: resource.ComposeTestCheckFunc(
+						testAccCheckUAAGroupExists(ref),
+						resource.TestCheckResourceAttr(ref, "display_name", "custom.write"),
+						resource.TestCheckResourceAttr(ref, "description", "Write access on custom api"),
+						resource.TestCheckResourceAttr(ref, "external_group.#", "2"),
+						resource.TestCheckTypeSetElemNestedAttrs(ref, "external_group.*", map[string]string{
+							"external_group": "cn=developers,ou=groups,dc=example,dc=org",
+							"origin":         "ldap",
+						}),
+						resource.TestCheckTypeSetElemNestedAttrs(ref, "external_group.*", map[string]string{
+							"external_group": "custom-writers",
+							"origin":         "saml",
+						}),
+					),
+				},
+
+				resource.TestStep{
+					ResourceName:      ref,
+					ImportState:       true,
+					ImportStateVerify: true,
+				},
+			},
+		})
+}
+
+func TestAccResUAAGroupMembership_normal(t *testing.T) {
+	ref := "cloudfoundry_uaa_group_membership.custom"
+
+	resource.Test(t,
+		resource.TestCase{
+			PreCheck:          func() { testAccPreCheck(t) },
+			ProviderFactories: testAccProvidersFactories,
+			CheckDestroy:      testAccCheckUAAGroupDestroyed("cloudfoundry_uaa_group.custom"),
+			Steps: []resource.TestStep{
+
+				resource.TestStep{
+					Config: uaaGroupMembershipResource,
+					Check: resource.ComposeTestCheckFunc(
+						resource.TestCheckResourceAttrPair(ref, "id", "cloudfoundry_uaa_group.custom", "id"),
+						resource.TestCheckResourceAttr(ref, "users.#", "1"),
+						resource.TestCheckResourceAttr(ref, "groups.#", "1"),
+						testAccCheckUAAGroupMembers(ref, 2),
+					),
+				},
+
+				resource.TestStep{
+					Config: uaaGroupMembershipResourceUpdate,
+					Check: resource.ComposeTestCheckFunc(
+						resource.TestCheckResourceAttr(ref, "users.#", "1"),
+						resource.TestCheckResourceAttr(ref, "groups.#", "0"),
+						testAccCheckUAAGroupMembers(ref, 1),
+					),
+				},
+
+				resource.TestStep{
+					ResourceName:      ref,
+					ImportState:       true,
+					ImportStateVerify: true,
+				},
+			},
+		})
+}
+
+func testAccCheckUAAGroupExists(resource string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources[resource]
+		if !ok {
+			return fmt.Errorf("uaa group '%s' not found in terraform state", resource)
+		}
+		var group uaaGroup
+		err := uaaDo(testSession(), "GET", fmt.Sprintf("/Groups/%s", rs.Primary.ID), nil, &group)
+		if err != nil {
+			return err
+		}
+		if group.DisplayName != rs.Primary.Attributes["display_name"] {
+			return fmt.Errorf("expected uaa group '%s' but found '%s'", rs.Primary.Attributes["display_name"], group.DisplayName)
+		}
+		return nil
+	}
+}
+
+func testAccCheckUAAGroupMembers(resource string, expected int) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources[resource]
+		if !ok {
+			return fmt.Errorf("uaa group membership '%s' not found in terraform state", resource)
+		}
+		members, err := getUAAGroupMembers(testSession(), rs.Primary.ID)
+		if err != nil {
+			return err
+		}
+		if len(members) != expected {
+			return fmt.Errorf("expected %d members in uaa group but found %d", expected, len(members))
+		}
+		return nil
+	}
+}
+
+func testAccCheckUAAGroupDestroyed(resource string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources[resource]
+		if !ok {
+			return nil
+		}
+		err := uaaDo(testSession(), "GET", fmt.Sprintf("/Groups/%s", rs.Primary.ID), nil, nil)
+		if err == nil {
+			return fmt.Errorf("uaa group %s still exists", rs.Primary.ID)
+		}
+		if !IsErrNotFound(err) {
+			return err
+		}
+		return nil
+	}
+}
@@ -0,0 +1,382 @@
+package cloudfoundry
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+const fakeUAADefaultZone = "uaa"
+
+// fakeUAA - in memory uaa serving identity providers, identity zones and clients, secrets are never given back as uaa does
+type fakeUAA struct {
+	fakeServer
+	providers map[string]map[string]interface{}
+	zones     map[string]map[string]interface{}
+	clients   map[string]map[string]interface{}
+	// active secrets of each client, current one first
+	clientSecrets map[string][]string
+	// status given on client secret change when set
+	secretStatus int
+	// zone header received on each request
+	zoneHeaders []string
+	lastID      int
+}
+
+func newFakeUAA() *fakeUAA {
+	f := &fakeUAA{
+		providers: make(map[string]map[string]interface{}),
+		zones:     make(map[string]map[string]interface{}),
+		clients:   make(map[string]map[string]interface{}),
+
+		clientSecrets: make(map[string][]string),
+	}
+	f.start(f.serve)
+	return f
+}
+
+func (f *fakeUAA) serve(w http.ResponseWriter, r *http.Request, parts []string) {
+	zone := r.Header.Get(uaaZoneHeader)
+	f.zoneHeaders = append(f.zoneHeaders, zone)
+	if zone == "" {
+		zone = fakeUAADefaultZone
+	}
+
+	var store map[string]map[string]interface{}
+	switch parts[0] {
+	case "identity-providers":
+		store = f.providers
+	case "identity-zones":
+		store = f.zones
+	case "oauth":
+		f.serveClients(w, r, parts[1:])
+		return
+	default:
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	var body map[string]interface{}
+	if r.Method == "POST" || r.Method == "PUT" {
+		if !f.readJSON(w, r, &body) {
+			return
+		}
+	}
+
+	if len(parts) == 1 {
+		if r.Method != "POST" {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		f.lastID++
+		body["id"] = fmt.Sprintf("%s-%d", parts[0], f.lastID)
+		if parts[0] == "identity-providers" {
+			body["identityZoneId"] = zone
+		}
+		store[body["id"].(string)] = body
+		f.write(w, http.StatusCreated, body)
+		return
+	}
+
+	current, ok := store[parts[1]]
+	if !ok || (parts[0] == "identity-providers" && current["identityZoneId"] != zone) {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	switch r.Method {
+	case "GET":
+		f.write(w, http.StatusOK, current)
+	case "PUT":
+		if r.Header.Get("If-Match") == "" {
+			w.WriteHeader(http.StatusPreconditionFailed)
+			return
+		}
+		body["id"] = current["id"]
+		if parts[0] == "identity-providers" {
+			body["identityZoneId"] = zone
+		}
+		store[parts[1]] = body
+		f.write(w, http.StatusOK, body)
+	case "DELETE":
+		delete(store, parts[1])
+		f.write(w, http.StatusOK, current)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func (f *fakeUAA) serveClients(w http.ResponseWriter, r *http.Request, parts []string) {
+	if len(parts) == 0 || parts[0] != "clients" {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	var body map[string]interface{}
+	if r.Method == "POST" || r.Method == "PUT" {
+		if !f.readJSON(w, r, &body) {
+			return
+		}
+	}
+
+	if len(parts) == 1 {
+		if r.Method != "POST" {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		id, _ := body["client_id"].(string)
+		secret, _ := body["client_secret"].(string)
+		delete(body, "client_secret")
+		f.clients[id] = body
+		f.clientSecrets[id] = []string{secret}
+		f.write(w, http.StatusCreated, body)
+		return
+	}
+
+	current, ok := f.clients[parts[1]]
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	switch {
+	case len(parts) == 3 && parts[2] == "secret" && r.Method == "PUT":
+		if f.secretStatus != 0 {
+			w.WriteHeader(f.secretStatus)
+			return
+		}
+		secrets := f.clientSecrets[parts[1]]
+		secret, _ := body["secret"].(string)
+		switch body["changeMode"] {
+		case uaaSecretChangeDelete:
+			secrets = secrets[:1]
+		case uaaSecretChangeAdd:
+			secrets = []string{secret, secrets[0]}
+		default:
+			secrets = []string{secret}
+		}
+		f.clientSecrets[parts[1]] = secrets
+		f.write(w, http.StatusOK, map[string]interface{}{"status": "ok"})
+	case len(parts) > 2:
+		w.WriteHeader(http.StatusNotFound)
+	case r.Method == "GET":
+		f.write(w, http.StatusOK, current)
+	case r.Method == "PUT":
+		f.clients[parts[1]] = body
+		f.write(w, http.StatusOK, body)
+	case r.Method == "DELETE":
+		delete(f.clients, parts[1])
+		delete(f.clientSecrets, parts[1])
+		f.write(w, http.StatusOK, current)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+// write - answer with obj without its secrets as uaa does
+func (f *fakeUAA) write(w http.ResponseWriter, status int, obj map[string]interface{}) {
+	var out map[string]interface{}
+	b, _ := json.Marshal(obj)
+	json.Unmarshal(b, &out)
+	if config, ok := out["config"].(map[string]interface{}); ok {
+		delete(config, "bindPassword")
+		delete(config, "relyingPartySecret")
+	}
+	f.writeJSON(w, status, out)
+}
+
+func (f *fakeUAA) provider(t *testing.T, id string) (map[string]interface{}, map[string]interface{}) {
+	f.Lock()
+	defer f.Unlock()
+	p, ok := f.providers[id]
+	if !ok {
+		t.Fatalf("identity provider %s not found in uaa", id)
+	}
+	return p, p["config"].(map[string]interface{})
+}
+
+func TestUAAIdentityProvider_LDAP(t *testing.T) {
+	uaa := newFakeUAA()
+	defer uaa.server.Close()
+	session := uaa.session()
+	ctx := context.Background()
+
+	r := resourceUAALDAPProvider()
+	d := schema.TestResourceDataRaw(t, r.Schema, map[string]interface{}{
+		"name":          "corporate ldap",
+		"url":           "ldaps://ldap.example.com:636",
+		"bind_user_dn":  "cn=admin,dc=example,dc=org",
+		"bind_password": "secret",
+		"attribute_mappings": map[string]interface{}{
+			"given_name":      "givenName",
+			"external_groups": "memberOf",
+		},
+		"external_groups_whitelist": []interface{}{"developers", "admins*"},
+	})
+
+	if diags := r.CreateContext(ctx, d, session); diags.HasError() {
+		t.Fatalf("create failed: %v", diags)
+	}
+	p, config := uaa.provider(t, d.Id())
+	if p["originKey"] != "ldap" || p["type"] != "ldap" || p["active"] != true {
+		t.Fatalf("unexpected identity provider created: %v", p)
+	}
+	if config["baseUrl"] != "ldaps://ldap.example.com:636" || config["bindPassword"] != "secret" ||
+		config["ldapProfileFile"] != "ldap/ldap-search-and-bind.xml" || config["groupMaxSearchDepth"] != float64(10) {
+		t.Fatalf("unexpected identity provider config: %v", config)
+	}
+	if p["identityZoneId"] != fakeUAADefaultZone {
+		t.Fatalf("expected provider in zone %s but found %v", fakeUAADefaultZone, p["identityZoneId"])
+	}
+	// secret is not given back by uaa, it must be kept from state
+	if v := d.Get("bind_password").(string); v != "secret" {
+		t.Fatalf("expected bind_password to be kept but found '%s'", v)
+	}
+	if v := d.Get("attribute_mappings.external_groups").(string); v != "memberOf" {
+		t.Fatalf("expected external_groups mapping but found '%s'", v)
+	}
+	if n := d.Get("external_groups_whitelist").(*schema.Set).Len(); n != 2 {
+		t.Fatalf("expected 2 whitelisted groups but found %d", n)
+	}
+
+	d.Set("active", false)
+	d.Set("group_profile", "ldap/ldap-groups-map-to-scopes.xml")
+	d.Set("group_search_base", "ou=groups,dc=example,dc=org")
+	if diags := r.UpdateContext(ctx, d, session); diags.HasError() {
+		t.Fatalf("update failed: %v", diags)
+	}
+	p, config = uaa.provider(t, d.Id())
+	if p["active"] != false || config["ldapGroupFile"] != "ldap/ldap-groups-map-to-scopes.xml" ||
+		config["groupSearchBase"] != "ou=groups,dc=example,dc=org" || config["bindPassword"] != "secret" {
+		t.Fatalf("unexpected identity provider after update: %v", p)
+	}
+
+	if diags := r.DeleteContext(ctx, d, session); diags.HasError() {
+		t.Fatalf("delete failed: %v", diags)
+	}
+	if diags := r.ReadContext(ctx, d, session); diags.HasError() || d.Id() != "" {
+		t.Fatalf("expected identity provider to be removed from state, diags: %v", diags)
+	}
+	for _, zone := range uaa.zoneHeaders {
+		if zone != "" {
+			t.Fatalf("expected requests in zone of uaa client but found zone %s", zone)
+		}
+	}
+}
+
+func TestUAAIdentityProvider_SAMLInZone(t *testing.T) {
+	uaa := newFakeUAA()
+	defer uaa.server.Close()
+	session := uaa.session()
+	ctx := context.Background()
+
+	zr := resourceUAAIdentityZone()
+	zd := schema.TestResourceDataRaw(t, zr.Schema, map[string]interface{}{
+		"subdomain": "tenant",
+		"name":      "Tenant",
+	})
+	if diags := zr.CreateContext(ctx, zd, session); diags.HasError() {
+		t.Fatalf("zone create failed: %v", diags)
+	}
+
+	r := resourceUAASAMLProvider()
+	d := schema.TestResourceDataRaw(t, r.Schema, map[string]interface{}{
+		"name":       "corporate saml",
+		"origin_key": "corporate",
+		"zone_id":    zd.Id(),
+		"metadata":   "https://idp.example.com/metadata",
+		"attribute_mappings": map[string]interface{}{
+			"external_groups": "groups",
+		},
+	})
+	if diags := r.CreateContext(ctx, d, session); diags.HasError() {
+		t.Fatalf("create failed: %v", diags)
+	}
+	p, config := uaa.provider(t, d.Id())
+	if p["originKey"] != "corporate" || p["type"] != "saml" || p["identityZoneId"] != zd.Id() {
+		t.Fatalf("unexpected identity provider created: %v", p)
+	}
+	if config["metaDataLocation"] != "https://idp.example.com/metadata" || config["showSamlLink"] != true {
+		t.Fatalf("unexpected identity provider config: %v", config)
+	}
+
+	d.Set("group_mapping_mode", "AS_SCOPES")
+	if diags := r.UpdateContext(ctx, d, session); diags.HasError() {
+		t.Fatalf("update failed: %v", diags)
+	}
+	if _, config = uaa.provider(t, d.Id()); config["groupMappingMode"] != "AS_SCOPES" {
+		t.Fatalf("unexpected identity provider config after update: %v", config)
+	}
+
+	// provider in another zone is imported with <zone id>/<provider id>
+	imported := r.Data(nil)
+	imported.SetId(computeID(zd.Id(), d.Id()))
+	states, err := r.Importer.StateContext(ctx, imported, session)
+	if err != nil {
+		t.Fatalf("import failed: %s", err)
+	}
+	if diags := r.ReadContext(ctx, states[0], session); diags.HasError() {
+		t.Fatalf("read after import failed: %v", diags)
+	}
+	if states[0].Id() != d.Id() || states[0].Get("origin_key") != "corporate" || states[0].Get("zone_id") != zd.Id() {
+		t.Fatalf("unexpected imported state: %v", states[0].State())
+	}
+
+	if diags := r.DeleteContext(ctx, d, session); diags.HasError() {
+		t.Fatalf("delete failed: %v", diags)
+	}
+	zd.Set("name", "Tenant renamed")
+	if diags := zr.UpdateContext(ctx, zd, session); diags.HasError() {
+		t.Fatalf("zone update failed: %v", diags)
+	}
+	if name := uaa.zones[zd.Id()]["name"]; name != "Tenant renamed" {
+		t.Fatalf("expected zone to be renamed but found %v", name)
+	}
+	if diags := zr.DeleteContext(ctx, zd, session); diags.HasError() {
+		t.Fatalf("zone delete failed: %v", diags)
+	}
+	if len(uaa.zones) != 0 || len(uaa.providers) != 0 {
+		t.Fatalf("expected zone and provider to be deleted")
+	}
+}
+
+func TestUAAIdentityProvider_OIDC(t *testing.T) {
+	uaa := newFakeUAA()
+	defer uaa.server.Close()
+	session := uaa.session()
+	ctx := context.Background()
+
+	r := resourceUAAOIDCProvider()
+	d := schema.TestResourceDataRaw(t, r.Schema, map[string]interface{}{
+		"name":          "corporate oidc",
+		"origin_key":    "oidc",
+		"discovery_url": "https://login.example.com/.well-known/openid-configuration",
+		"client_id":     "cf",
+		"client_secret": "secret",
+		"scopes":        []interface{}{"openid", "email"},
+	})
+	if diags := r.CreateContext(ctx, d, session); diags.HasError() {
+		t.Fatalf("create failed: %v", diags)
+	}
+	p, config := uaa.provider(t, d.Id())
+	if p["type"] != "oidc1.0" || config["relyingPartyId"] != "cf" || config["relyingPartySecret"] != "secret" ||
+		config["responseType"] != "code" {
+		t.Fatalf("unexpected identity provider created: %v", p)
+	}
+	if _, ok := config["authUrl"]; ok {
+		t.Fatalf("expected unset auth_url to be left out of config: %v", config)
+	}
+	if v := d.Get("client_secret").(string); v != "secret" {
+		t.Fatalf("expected client_secret to be kept but found '%s'", v)
+	}
+	if n := d.Get("scopes").(*schema.Set).Len(); n != 2 {
+		t.Fatalf("expected 2 scopes but found %d", n)
+	}
+
+	// provider deleted outside of terraform is removed from state
+	delete(uaa.providers, d.Id())
+	if diags := r.ReadContext(ctx, d, session); diags.HasError() || d.Id() != "" {
+		t.Fatalf("expected identity provider to be removed from state, diags: %v", diags)
+	}
+}
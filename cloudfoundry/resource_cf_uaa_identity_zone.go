@@ -0,0 +1,123 @@
+package cloudfoundry
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"github.com/terraform-providers/terraform-provider-cloudfoundry/cloudfoundry/managers"
+)
+
+func resourceUAAIdentityZone() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceUAAIdentityZoneCreate,
+		ReadContext:   resourceUAAIdentityZoneRead,
+		UpdateContext: resourceUAAIdentityZoneUpdate,
+		DeleteContext: resourceUAAIdentityZoneDelete,
+
+		Importer: &schema.ResourceImporter{
+			StateContext: ImportReadContext(resourceUAAIdentityZoneRead),
+		},
+
+		Schema: map[string]*schema.Schema{
+			"subdomain": &schema.Schema{
+				Type:         schema.TypeString,
+				Required:     true,
+				Description:  "Subdomain of uaa domain serving the zone",
+				ValidateFunc: validation.NoZeroValues,
+			},
+			"name": &schema.Schema{
+				Type:         schema.TypeString,
+				Required:     true,
+				ValidateFunc: validation.NoZeroValues,
+			},
+			"description": &schema.Schema{
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+			"active": &schema.Schema{
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  true,
+			},
+		},
+	}
+}
+
+// uaaIdentityZone - identity zone as given by uaa api, zone config is left to uaa defaults
+type uaaIdentityZone struct {
+	ID          string `json:"id,omitempty"`
+	Subdomain   string `json:"subdomain"`
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Active      bool   `json:"active"`
+}
+
+func resourceUAAIdentityZoneCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	session := meta.(*managers.Session)
+
+	var zone uaaIdentityZone
+	err := uaaDo(session, "POST", "/identity-zones", uaaIdentityZone{
+		Subdomain:   d.Get("subdomain").(string),
+		Name:        d.Get("name").(string),
+		Description: d.Get("description").(string),
+		Active:      d.Get("active").(bool),
+	}, &zone)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	d.SetId(zone.ID)
+	return resourceUAAIdentityZoneRead(ctx, d, meta)
+}
+
+func resourceUAAIdentityZoneRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	session := meta.(*managers.Session)
+
+	var zone uaaIdentityZone
+	err := uaaDo(session, "GET", fmt.Sprintf("/identity-zones/%s", d.Id()), nil, &zone)
+	if err != nil {
+		if IsErrNotFound(err) {
+			d.SetId("")
+			return nil
+		}
+		return diag.FromErr(err)
+	}
+	d.Set("subdomain", zone.Subdomain)
+	d.Set("name", zone.Name)
+	d.Set("description", zone.Description)
+	d.Set("active", zone.Active)
+	return nil
+}
+
+func resourceUAAIdentityZoneUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	session := meta.(*managers.Session)
+	path := fmt.Sprintf("/identity-zones/%s", d.Id())
+
+	// zone is sent back as given by uaa to keep its config
+	var zone map[string]interface{}
+	err := uaaDo(session, "GET", path, nil, &zone)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	zone["subdomain"] = d.Get("subdomain").(string)
+	zone["name"] = d.Get("name").(string)
+	zone["description"] = d.Get("description").(string)
+	zone["active"] = d.Get("active").(bool)
+	err = uaaDo(session, "PUT", path, zone, nil)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	return resourceUAAIdentityZoneRead(ctx, d, meta)
+}
+
+func resourceUAAIdentityZoneDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	session := meta.(*managers.Session)
+
+	err := uaaDo(session, "DELETE", fmt.Sprintf("/identity-zones/%s", d.Id()), nil, nil)
+	if err != nil && !IsErrNotFound(err) {
+		return diag.FromErr(err)
+	}
+	return nil
+}
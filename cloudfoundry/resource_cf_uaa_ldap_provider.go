@@ -0,0 +1,127 @@
+package cloudfoundry
+
+import (
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+var uaaLDAPProfiles = []string{
+	"ldap/ldap-simple-bind.xml",
+	"ldap/ldap-search-and-bind.xml",
+	"ldap/ldap-search-and-compare.xml",
+}
+
+var uaaLDAPGroupProfiles = []string{
+	"ldap/ldap-groups-null.xml",
+	"ldap/ldap-groups-as-scopes.xml",
+	"ldap/ldap-groups-map-to-scopes.xml",
+}
+
+// resourceUAALDAPProvider - uaa only allows one ldap provider by zone, its origin is always ldap
+func resourceUAALDAPProvider() *schema.Resource {
+	return resourceUAAIdentityProvider(uaaProviderLDAP, uaaProviderLDAP, map[string]identityProviderField{
+		"url": {
+			Key: "baseUrl",
+			Schema: &schema.Schema{
+				Type:         schema.TypeString,
+				Required:     true,
+				Description:  "Ldap server url, e.g. ldaps://ldap.example.com:636",
+				ValidateFunc: validation.NoZeroValues,
+			},
+		},
+		"profile": {
+			Key: "ldapProfileFile",
+			Schema: &schema.Schema{
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      "ldap/ldap-search-and-bind.xml",
+				ValidateFunc: validation.StringInSlice(uaaLDAPProfiles, false),
+			},
+		},
+		"bind_user_dn": {
+			Key: "bindUserDn",
+			Schema: &schema.Schema{
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+		},
+		"bind_password": {
+			Key: "bindPassword",
+			Schema: &schema.Schema{
+				Type:      schema.TypeString,
+				Optional:  true,
+				Sensitive: true,
+			},
+		},
+		"user_search_base": {
+			Key: "userSearchBase",
+			Schema: &schema.Schema{
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+		},
+		"user_search_filter": {
+			Key: "userSearchFilter",
+			Schema: &schema.Schema{
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+		},
+		"user_dn_pattern": {
+			Key: "userDNPattern",
+			Schema: &schema.Schema{
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "Pattern to build user dn with simple bind profile, e.g. cn={0},ou=users,dc=example,dc=org",
+			},
+		},
+		"mail_attribute_name": {
+			Key: "mailAttributeName",
+			Schema: &schema.Schema{
+				Type:     schema.TypeString,
+				Optional: true,
+				Default:  "mail",
+			},
+		},
+		"group_profile": {
+			Key: "ldapGroupFile",
+			Schema: &schema.Schema{
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      "ldap/ldap-groups-null.xml",
+				ValidateFunc: validation.StringInSlice(uaaLDAPGroupProfiles, false),
+			},
+		},
+		"group_search_base": {
+			Key: "groupSearchBase",
+			Schema: &schema.Schema{
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+		},
+		"group_search_filter": {
+			Key: "groupSearchFilter",
+			Schema: &schema.Schema{
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+		},
+		"group_max_search_depth": {
+			Key: "groupMaxSearchDepth",
+			Schema: &schema.Schema{
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      10,
+				ValidateFunc: validation.IntAtLeast(1),
+			},
+		},
+		"skip_ssl_validation": {
+			Key: "skipSSLVerification",
+			Schema: &schema.Schema{
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+			},
+		},
+	})
+}
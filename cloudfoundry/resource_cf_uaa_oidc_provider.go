@@ -0,0 +1,114 @@
+package cloudfoundry
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+func resourceUAAOIDCProvider() *schema.Resource {
+	urlField := func(key string, description string) identityProviderField {
+		return identityProviderField{
+			Key: key,
+			Schema: &schema.Schema{
+				Type:         schema.TypeString,
+				Optional:     true,
+				Description:  description,
+				ValidateFunc: validation.IsURLWithHTTPorHTTPS,
+			},
+		}
+	}
+	r := resourceUAAIdentityProvider(uaaProviderOIDC, "", map[string]identityProviderField{
+		"discovery_url": urlField("discoveryUrl", "OpenID connect discovery url, other urls are discovered when set"),
+		"auth_url":      urlField("authUrl", ""),
+		"token_url":     urlField("tokenUrl", ""),
+		"token_key_url": urlField("tokenKeyUrl", ""),
+		"user_info_url": urlField("userInfoUrl", ""),
+		"token_key": {
+			Key: "tokenKey",
+			Schema: &schema.Schema{
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "Verification key of tokens, when token_key_url is not given",
+			},
+		},
+		"issuer": {
+			Key: "issuer",
+			Schema: &schema.Schema{
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+		},
+		"client_id": {
+			Key: "relyingPartyId",
+			Schema: &schema.Schema{
+				Type:         schema.TypeString,
+				Required:     true,
+				ValidateFunc: validation.NoZeroValues,
+			},
+		},
+		"client_secret": {
+			Key: "relyingPartySecret",
+			Schema: &schema.Schema{
+				Type:      schema.TypeString,
+				Optional:  true,
+				Sensitive: true,
+			},
+		},
+		"scopes": {
+			Key: "scopes",
+			Schema: &schema.Schema{
+				Type:     schema.TypeSet,
+				Optional: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+				Set:      schema.HashString,
+			},
+		},
+		"response_type": {
+			Key: "responseType",
+			Schema: &schema.Schema{
+				Type:     schema.TypeString,
+				Optional: true,
+				Default:  "code",
+			},
+		},
+		"show_link": {
+			Key: "showLinkText",
+			Schema: &schema.Schema{
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  true,
+			},
+		},
+		"link_text": {
+			Key: "linkText",
+			Schema: &schema.Schema{
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+		},
+		"skip_ssl_validation": {
+			Key: "skipSslValidation",
+			Schema: &schema.Schema{
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+			},
+		},
+	})
+	r.CustomizeDiff = func(ctx context.Context, diff *schema.ResourceDiff, meta interface{}) error {
+		if !diff.NewValueKnown("discovery_url") || !diff.NewValueKnown("auth_url") || !diff.NewValueKnown("token_url") {
+			return nil
+		}
+		if diff.Get("discovery_url").(string) != "" {
+			return nil
+		}
+		if diff.Get("auth_url").(string) == "" || diff.Get("token_url").(string) == "" {
+			return fmt.Errorf("auth_url and token_url are required when discovery_url is not set")
+		}
+		return nil
+	}
+	return r
+}
@@ -0,0 +1,85 @@
+package cloudfoundry
+
+import (
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+func resourceUAASAMLProvider() *schema.Resource {
+	return resourceUAAIdentityProvider(uaaProviderSAML, "", map[string]identityProviderField{
+		"metadata": {
+			Key: "metaDataLocation",
+			Schema: &schema.Schema{
+				Type:         schema.TypeString,
+				Required:     true,
+				Description:  "Url or xml content of identity provider metadata",
+				ValidateFunc: validation.NoZeroValues,
+			},
+		},
+		"entity_alias": {
+			Key: "idpEntityAlias",
+			Schema: &schema.Schema{
+				Type:     schema.TypeString,
+				Optional: true,
+				Computed: true,
+			},
+		},
+		"name_id": {
+			Key: "nameID",
+			Schema: &schema.Schema{
+				Type:     schema.TypeString,
+				Optional: true,
+				Default:  "urn:oasis:names:tc:SAML:1.1:nameid-format:unspecified",
+			},
+		},
+		"assertion_consumer_index": {
+			Key: "assertionConsumerIndex",
+			Schema: &schema.Schema{
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      0,
+				ValidateFunc: validation.IntAtLeast(0),
+			},
+		},
+		"metadata_trust_check": {
+			Key: "metadataTrustCheck",
+			Schema: &schema.Schema{
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+			},
+		},
+		"show_link": {
+			Key: "showSamlLink",
+			Schema: &schema.Schema{
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  true,
+			},
+		},
+		"link_text": {
+			Key: "linkText",
+			Schema: &schema.Schema{
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+		},
+		"group_mapping_mode": {
+			Key: "groupMappingMode",
+			Schema: &schema.Schema{
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      "EXPLICITLY_MAPPED",
+				ValidateFunc: validation.StringInSlice([]string{"EXPLICITLY_MAPPED", "AS_SCOPES"}, false),
+			},
+		},
+		"skip_ssl_validation": {
+			Key: "skipSslValidation",
+			Schema: &schema.Schema{
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+			},
+		},
+	})
+}
@@ -4,9 +4,12 @@ import (
 	"code.cloudfoundry.org/cli/api/cloudcontroller/ccv2"
 	"code.cloudfoundry.org/cli/api/uaa"
 	"context"
+	"encoding/json"
+	"fmt"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/terraform-providers/terraform-provider-cloudfoundry/cloudfoundry/managers"
+	"net/url"
 )
 
 func resourceUser() *schema.Resource {
@@ -18,7 +21,7 @@ func resourceUser() *schema.Resource {
 		DeleteContext: resourceUserDelete,
 
 		Importer: &schema.ResourceImporter{
-			StateContext: ImportReadContext(resourceUserRead),
+			StateContext: resourceUserImport,
 		},
 		Schema: map[string]*schema.Schema{
 			"name": &schema.Schema{
@@ -62,10 +65,91 @@ func resourceUser() *schema.Resource {
 				Elem:       &schema.Schema{Type: schema.TypeString},
 				Set:        resourceStringHash,
 			},
+			"active": &schema.Schema{
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     true,
+				Description: "Inactive users can't log in",
+			},
+			"verified": &schema.Schema{
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  true,
+			},
+			"phone_numbers": &schema.Schema{
+				Type:     schema.TypeSet,
+				Optional: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+				Set:      schema.HashString,
+			},
+			"password_change_required": &schema.Schema{
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "Force user to change password on next login, uaa unsets it once password is changed",
+			},
+			"lockout_reset": &schema.Schema{
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "Any change of this value unlocks user locked out after too many failed logins",
+			},
+			"deactivate_on_destroy": &schema.Schema{
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "Deactivate user instead of deleting it on destroy to keep its audit history",
+			},
 		},
 	}
 }
 
+// uaaUser - user as given by uaa api with attributes not available in uaa.User
+type uaaUser struct {
+	uaa.User
+	Active       bool             `json:"active"`
+	Verified     bool             `json:"verified"`
+	PhoneNumbers []uaaPhoneNumber `json:"phoneNumbers,omitempty"`
+}
+
+type uaaPhoneNumber struct {
+	Value string `json:"value"`
+}
+
+// resourceUserImport - user is imported by its guid or by <origin>/<username>
+func resourceUserImport(ctx context.Context, d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	session := meta.(*managers.Session)
+	if origin, username, err := parseID(d.Id()); err == nil {
+		id, err := getUAAUserID(session, username, origin)
+		if err != nil {
+			return []*schema.ResourceData{}, err
+		}
+		d.SetId(id)
+	}
+	return ImportReadContext(resourceUserRead)(ctx, d, meta)
+}
+
+func getUAAUserID(session *managers.Session, username string, origin string) (string, error) {
+	// json strings are valid scim filter strings
+	u, _ := json.Marshal(username)
+	o, _ := json.Marshal(origin)
+	query := url.Values{}
+	query.Set("filter", fmt.Sprintf("userName eq %s and origin eq %s", u, o))
+	query.Set("attributes", "id")
+	var page struct {
+		Resources []struct {
+			ID string `json:"id"`
+		} `json:"resources"`
+	}
+	err := uaaDo(session, "GET", "/Users?"+query.Encode(), nil, &page)
+	if err != nil {
+		return "", err
+	}
+	if len(page.Resources) == 0 {
+		return "", fmt.Errorf("user '%s' with origin '%s' not found in uaa", username, origin)
+	}
+	return page.Resources[0].ID, nil
+}
+
 func resourceUserCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	session := meta.(*managers.Session)
 	if session == nil {
@@ -176,7 +260,6 @@ func resourceUserRead(ctx context.Context, d *schema.ResourceData, meta interfac
 
 	session := meta.(*managers.Session)
 
-	umuaa := session.ClientUAA
 	um := session.ClientV2
 	id := d.Id()
 
@@ -199,7 +282,8 @@ func resourceUserRead(ctx context.Context, d *schema.ResourceData, meta interfac
 	}
 
 	// 2. check  user exists in UAA
-	user, err := umuaa.GetUser(id)
+	var user uaaUser
+	err = uaaDo(session, "GET", fmt.Sprintf("/Users/%s", id), nil, &user)
 	if err != nil {
 		if IsErrNotFound(err) {
 			d.SetId("")
@@ -212,7 +296,19 @@ func resourceUserRead(ctx context.Context, d *schema.ResourceData, meta interfac
 	d.Set("origin", user.Origin)
 	d.Set("given_name", user.Name.GivenName)
 	d.Set("family_name", user.Name.FamilyName)
-	d.Set("email", user.Emails[0].Value)
+	if len(user.Emails) > 0 {
+		d.Set("email", user.Emails[0].Value)
+	}
+	d.Set("active", user.Active)
+	d.Set("verified", user.Verified)
+	phoneNumbers := make([]string, len(user.PhoneNumbers))
+	for i, p := range user.PhoneNumbers {
+		phoneNumbers[i] = p.Value
+	}
+	d.Set("phone_numbers", phoneNumbers)
+	// not given by uaa, set to keep state of users created before these attributes existed in sync with defaults
+	d.Set("password_change_required", d.Get("password_change_required").(bool))
+	d.Set("deactivate_on_destroy", d.Get("deactivate_on_destroy").(bool))
 
 	tfGroups := d.Get("groups").(*schema.Set).List()
 	groups := user.Groups
@@ -235,54 +331,15 @@ func resourceUserUpdate(ctx context.Context, d *schema.ResourceData, meta interf
 	id := d.Id()
 	umuaa := session.ClientUAA
 
-	if !d.IsNewResource() {
-
-		updateUserDetail := false
-		name := d.Get("name").(string)
-		if d.HasChange("name") {
-			updateUserDetail = true
-		}
-		givenName := d.Get("given_name").(string)
-		if d.HasChange("given_name") {
-			updateUserDetail = true
-		}
-		familyName := d.Get("family_name").(string)
-		if d.HasChange("family_name") {
-			updateUserDetail = true
-		}
-
-		var username uaa.UserName
-		if givenName != "" || familyName != "" {
-			username = uaa.UserName{
-				GivenName:  givenName,
-				FamilyName: familyName,
-			}
-		}
-
-		emails := []uaa.Email{
-			{
-				Value:   d.Get("email").(string),
-				Primary: true,
-			},
-		}
-
-		if d.HasChange("email") {
-			updateUserDetail = true
-		}
-
-		if updateUserDetail {
-			_, err := umuaa.UpdateUser(uaa.User{
-				ID:       id,
-				Username: name,
-				Emails:   emails,
-				Name:     username,
-				Origin:   d.Get("origin").(string),
-			})
-			if err != nil {
-				return diag.FromErr(err)
-			}
+	// an existing user found on create is updated too
+	if d.IsNewResource() || d.HasChanges("name", "given_name", "family_name", "email", "active", "verified", "phone_numbers") {
+		err := updateUAAUser(session, d, d.Get("active").(bool))
+		if err != nil {
+			return diag.FromErr(err)
 		}
+	}
 
+	if !d.IsNewResource() {
 		updatePassword, oldPassword, newPassword := getResourceChange("password", d)
 		if updatePassword {
 			err := umuaa.ChangeUserPassword(id, oldPassword, newPassword)
@@ -292,6 +349,24 @@ func resourceUserUpdate(ctx context.Context, d *schema.ResourceData, meta interf
 		}
 	}
 
+	// uaa only allows to require a password change, it is unset by uaa once user changed its password
+	if d.HasChange("password_change_required") && d.Get("password_change_required").(bool) {
+		err := uaaDo(session, "PATCH", fmt.Sprintf("/Users/%s/status", id), map[string]bool{
+			"passwordChangeRequired": true,
+		}, nil)
+		if err != nil {
+			return diag.FromErr(err)
+		}
+	}
+	if !d.IsNewResource() && d.HasChange("lockout_reset") {
+		err := uaaDo(session, "PATCH", fmt.Sprintf("/Users/%s/status", id), map[string]bool{
+			"locked": false,
+		}, nil)
+		if err != nil {
+			return diag.FromErr(err)
+		}
+	}
+
 	old, new := d.GetChange("groups")
 	rolesToDelete, rolesToAdd := getListChanges(old, new)
 
@@ -311,9 +386,49 @@ func resourceUserUpdate(ctx context.Context, d *schema.ResourceData, meta interf
 	return nil
 }
 
+// updateUAAUser - update user details, user is sent back as given by uaa to keep attributes not managed here
+func updateUAAUser(session *managers.Session, d *schema.ResourceData, active bool) error {
+	path := fmt.Sprintf("/Users/%s", d.Id())
+	var user map[string]interface{}
+	err := uaaDo(session, "GET", path, nil, &user)
+	if err != nil {
+		return err
+	}
+	phoneNumbers := make([]uaaPhoneNumber, 0)
+	for _, p := range d.Get("phone_numbers").(*schema.Set).List() {
+		phoneNumbers = append(phoneNumbers, uaaPhoneNumber{Value: p.(string)})
+	}
+	user["userName"] = d.Get("name").(string)
+	givenName := d.Get("given_name").(string)
+	familyName := d.Get("family_name").(string)
+	if givenName != "" || familyName != "" {
+		user["name"] = uaa.UserName{
+			GivenName:  givenName,
+			FamilyName: familyName,
+		}
+	}
+	user["emails"] = []uaa.Email{
+		{
+			Value:   d.Get("email").(string),
+			Primary: true,
+		},
+	}
+	user["phoneNumbers"] = phoneNumbers
+	user["active"] = active
+	user["verified"] = d.Get("verified").(bool)
+	return uaaDo(session, "PUT", path, user, nil)
+}
+
 func resourceUserDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	session := meta.(*managers.Session)
 	id := d.Id()
+	if d.Get("deactivate_on_destroy").(bool) {
+		err := updateUAAUser(session, d, false)
+		if err != nil && !IsErrNotFound(err) {
+			return diag.FromErr(err)
+		}
+		return nil
+	}
 	err := session.ClientUAA.DeleteUser(id)
 	if err != nil {
 		return diag.FromErr(err)
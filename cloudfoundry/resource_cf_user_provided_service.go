@@ -3,15 +3,25 @@ package cloudfoundry
 import (
 	"context"
 	"encoding/json"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 
 	"code.cloudfoundry.org/cli/api/cloudcontroller/ccv2"
+	"code.cloudfoundry.org/cli/api/cloudcontroller/ccv2/constant"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/structure"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 	"github.com/terraform-providers/terraform-provider-cloudfoundry/cloudfoundry/managers"
+	"github.com/terraform-providers/terraform-provider-cloudfoundry/cloudfoundry/managers/appdeployers"
 )
 
+// restageBoundAppsConcurrency - max number of bound apps restaged at the same time
+const restageBoundAppsConcurrency = 4
+
 func resourceUserProvidedService() *schema.Resource {
 
 	return &schema.Resource{
@@ -25,6 +35,10 @@ func resourceUserProvidedService() *schema.Resource {
 			StateContext: ImportReadContext(resourceUserProvidedServiceRead),
 		},
 
+		Timeouts: &schema.ResourceTimeout{
+			Update: schema.DefaultTimeout(15 * time.Minute),
+		},
+
 		Schema: map[string]*schema.Schema{
 
 			"name": &schema.Schema{
@@ -78,6 +92,26 @@ func resourceUserProvidedService() *schema.Resource {
 				Elem:     &schema.Schema{Type: schema.TypeString},
 				Set:      schema.HashString,
 			},
+			"restage_bound_apps": &schema.Schema{
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "Restage apps bound to service when credentials change, with deploy strategy recorded on each app",
+			},
+			"restage_pending_apps": &schema.Schema{
+				Type:        schema.TypeSet,
+				Computed:    true,
+				Description: "Bound apps which failed to be restaged with current credentials, they are restaged again on next apply",
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Set:         schema.HashString,
+			},
+		},
+
+		CustomizeDiff: func(ctx context.Context, diff *schema.ResourceDiff, meta interface{}) error {
+			if diff.Get("restage_bound_apps").(bool) && diff.Get("restage_pending_apps").(*schema.Set).Len() > 0 {
+				return diff.SetNewComputed("restage_pending_apps")
+			}
+			return nil
 		},
 	}
 }
@@ -225,7 +259,107 @@ func resourceUserProvidedServiceUpdate(ctx context.Context, d *schema.ResourceDa
 		SyslogDrainUrl:  syslogDrainURL,
 		Credentials:     credentials,
 	})
-	return diag.FromErr(err)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	if !d.Get("restage_bound_apps").(bool) {
+		d.Set("restage_pending_apps", []interface{}{})
+		return nil
+	}
+	// apps which failed to be restaged on a previous apply are the only ones left with old credentials,
+	// they are taken from state as restage_pending_apps is unknown in diff when it is not empty
+	old, _ := d.GetChange("restage_pending_apps")
+	pending := schema.NewSet(schema.HashString, old.(*schema.Set).List())
+	appGUIDs := make([]string, 0)
+	for _, appGUID := range pending.List() {
+		appGUIDs = append(appGUIDs, appGUID.(string))
+	}
+	if d.HasChange("credentials") || d.HasChange("credentials_json") {
+		appGUIDs, err = getBoundAppGUIDs(session, d.Id())
+		if err != nil {
+			// credentials are updated, restage of apps known so far is retried on next apply
+			for _, appGUID := range appGUIDs {
+				pending.Add(appGUID)
+			}
+			d.Set("restage_pending_apps", pending)
+			return diag.FromErr(err)
+		}
+	}
+	left, diags := restageBoundApps(ctx, session, d.Id(), appGUIDs, d.Timeout(schema.TimeoutUpdate))
+	d.Set("restage_pending_apps", left)
+	return diags
+}
+
+// getBoundAppGUIDs - guids of apps bound to a service instance
+func getBoundAppGUIDs(session *managers.Session, serviceID string) ([]string, error) {
+	appGUIDs := make([]string, 0)
+	path := fmt.Sprintf("/v3/service_credential_bindings?type=app&service_instance_guids=%s", serviceID)
+	err := v3GetAll(session, path, func(resources json.RawMessage) error {
+		var page []v3ServiceCredentialBinding
+		err := json.Unmarshal(resources, &page)
+		if err != nil {
+			return err
+		}
+		for _, b := range page {
+			appGUIDs = append(appGUIDs, b.Relationships.App.GUID())
+		}
+		return nil
+	})
+	return appGUIDs, err
+}
+
+// restageBoundApps - restage apps bound to a service instance to let them have new credentials,
+// at most restageBoundAppsConcurrency apps are restaged at the same time.
+// All failures are given back along with apps left to restage
+func restageBoundApps(ctx context.Context, session *managers.Session, serviceID string, appGUIDs []string, timeout time.Duration) ([]string, diag.Diagnostics) {
+	restaged := make(map[string]bool)
+	var mutex sync.Mutex
+	diags := doConcurrently(ctx, restageBoundAppsConcurrency, appGUIDs, func(appGUID string) error {
+		err := restageBoundApp(session, appGUID, timeout)
+		if err != nil {
+			return fmt.Errorf("restaging app %s bound to service instance %s failed: %s", appGUID, serviceID, err)
+		}
+		mutex.Lock()
+		defer mutex.Unlock()
+		restaged[appGUID] = true
+		return nil
+	})
+	// apps failed or not restaged before timeout are restaged on next apply
+	pending := make([]string, 0)
+	for _, appGUID := range appGUIDs {
+		if !restaged[appGUID] {
+			pending = append(pending, appGUID)
+		}
+	}
+	return pending, diags
+}
+
+// restageBoundApp - restage app with its recorded strategy, stopped app takes new credentials on its next start.
+// Strategy creating a new app is replaced by rolling one, app resource would lose track of its app otherwise
+func restageBoundApp(session *managers.Session, appGUID string, timeout time.Duration) error {
+	app, _, err := session.ClientV2.GetApplication(appGUID)
+	if err != nil {
+		if IsErrNotFound(err) {
+			return nil
+		}
+		return err
+	}
+	if app.State == constant.ApplicationStopped {
+		return nil
+	}
+	strategy, err := appRecordedStrategy(session, appGUID)
+	if err != nil {
+		return err
+	}
+	deployer := appInPlaceDeployer(session, strategy)
+	log.Printf("[INFO] Restaging app %s with strategy %s to take new service credentials", app.Name, deployer.Names()[0])
+	_, err = deployer.Restage(appdeployers.AppDeploy{
+		App:          app,
+		StageTimeout: timeout,
+		StartTimeout: timeout,
+		BindTimeout:  timeout,
+	})
+	return err
 }
 
 func resourceUserProvidedServiceDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
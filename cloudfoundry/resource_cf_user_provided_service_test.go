@@ -3,11 +3,16 @@ package cloudfoundry
 import (
 	"code.cloudfoundry.org/cli/api/cloudcontroller/ccv2"
 	"code.cloudfoundry.org/cli/api/cloudcontroller/ccv2/constant"
+	"context"
 	"fmt"
 	"github.com/terraform-providers/terraform-provider-cloudfoundry/cloudfoundry/managers"
+	"net/http"
+	"sort"
+	"strings"
 	"testing"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
 )
 
@@ -120,6 +125,33 @@ resource "cloudfoundry_user_provided_service" "complex" {
 }
 `
 
+const userProvidedServiceRestageResource = `
+resource "cloudfoundry_user_provided_service" "restaged" {
+  name = "restaged"
+  space = "%s"
+  credentials = {
+    "password" = "%s"
+  }
+  restage_bound_apps = true
+}
+
+resource "cloudfoundry_app" "dummy-app-restaged" {
+  name = "dummy-app-restaged"
+  buildpack = "binary_buildpack"
+  space = "%s"
+  memory = "64"
+  disk_quota = "512"
+  timeout = 1800
+  path = "%s"
+  strategy = "rolling"
+}
+
+resource "cloudfoundry_service_binding" "restaged" {
+  app = cloudfoundry_app.dummy-app-restaged.id
+  service_instance = cloudfoundry_user_provided_service.restaged.id
+}
+`
+
 func TestAccResUserProvidedService_normal(t *testing.T) {
 
 	ref := "cloudfoundry_user_provided_service.mq"
@@ -199,6 +231,227 @@ func TestAccResUserProvidedService_complex(t *testing.T) {
 		})
 }
 
+func TestAccResUserProvidedService_restageBoundApps(t *testing.T) {
+	spaceID, _ := defaultTestSpace(t)
+
+	ref := "cloudfoundry_user_provided_service.restaged"
+	refApp := "cloudfoundry_app.dummy-app-restaged"
+	droplet := ""
+	resource.Test(t,
+		resource.TestCase{
+			PreCheck:          func() { testAccPreCheck(t) },
+			ProviderFactories: testAccProvidersFactories,
+			CheckDestroy: func(s *terraform.State) error {
+				session := testAccProvider.Meta().(*managers.Session)
+				svcs, _, err := session.ClientV2.GetServiceInstances(ccv2.FilterByName("restaged"), ccv2.FilterEqual(constant.SpaceGUIDFilter, spaceID))
+				if err != nil {
+					return err
+				}
+				if len(svcs) > 0 {
+					return fmt.Errorf("user provided service with name 'restaged' still exists in cloud foundry")
+				}
+				return nil
+			},
+			Steps: []resource.TestStep{
+
+				resource.TestStep{
+					Config: fmt.Sprintf(userProvidedServiceRestageResource, spaceID, "pwd", spaceID, appPath),
+					Check: resource.ComposeTestCheckFunc(
+						testAccCheckUserProvidedServiceExists(ref),
+						resource.TestCheckResourceAttr(ref, "restage_bound_apps", "true"),
+						testAccCheckAppRecordedStrategy(refApp, "rolling"),
+						testAccCheckAppDroplet(refApp, &droplet, false),
+					),
+				},
+
+				resource.TestStep{
+					Config: fmt.Sprintf(userProvidedServiceRestageResource, spaceID, "new-pwd", spaceID, appPath),
+					Check: resource.ComposeTestCheckFunc(
+						resource.TestCheckResourceAttr(ref, "credentials.password", "new-pwd"),
+						resource.TestCheckResourceAttr(ref, "restage_pending_apps.#", "0"),
+						testAccCheckAppDroplet(refApp, &droplet, true),
+					),
+				},
+			},
+		})
+}
+
+// fakeBoundAppsCC - cloud controller serving a user provided service with its bound apps,
+// apps are stopped so restaging them only fetches them
+type fakeBoundAppsCC struct {
+	fakeServer
+	apps []string
+	// apps which can't be fetched to be restaged
+	failing map[string]bool
+	// status answered when listing bindings, 0 when given back
+	bindingsStatus int
+	// apps fetched to be restaged
+	restaged []string
+}
+
+func newFakeBoundAppsCC(apps ...string) *fakeBoundAppsCC {
+	f := &fakeBoundAppsCC{
+		apps:    apps,
+		failing: make(map[string]bool),
+	}
+	f.start(f.serve)
+	return f
+}
+
+func (f *fakeBoundAppsCC) serve(w http.ResponseWriter, r *http.Request, parts []string) {
+	switch {
+	case len(parts) == 3 && parts[1] == "user_provided_service_instances" && r.Method == "PUT":
+		f.writeJSON(w, http.StatusCreated, map[string]interface{}{
+			"metadata": map[string]interface{}{"guid": parts[2]},
+			"entity":   map[string]interface{}{},
+		})
+	case len(parts) == 2 && parts[1] == "service_credential_bindings":
+		if f.bindingsStatus != 0 {
+			f.writeError(w, f.bindingsStatus, "bindings are not available")
+			return
+		}
+		bindings := make([]interface{}, 0)
+		for _, app := range f.apps {
+			bindings = append(bindings, map[string]interface{}{
+				"relationships": map[string]interface{}{"app": newV3Relationship(app)},
+			})
+		}
+		f.writeJSON(w, http.StatusOK, map[string]interface{}{"resources": bindings})
+	case len(parts) == 3 && parts[1] == "apps":
+		f.restaged = append(f.restaged, parts[2])
+		if f.failing[parts[2]] {
+			f.writeJSON(w, http.StatusBadGateway, map[string]interface{}{
+				"code":        10001,
+				"description": "app is not available",
+				"error_code":  "CF-Unavailable",
+			})
+			return
+		}
+		f.writeJSON(w, http.StatusOK, map[string]interface{}{
+			"metadata": map[string]interface{}{"guid": parts[2]},
+			"entity":   map[string]interface{}{"name": parts[2], "state": "STOPPED"},
+		})
+	default:
+		f.writeJSON(w, http.StatusNotFound, nil)
+	}
+}
+
+// apply - apply user provided service config on state as terraform does, state and restaged apps are given back
+func (f *fakeBoundAppsCC) apply(t *testing.T, state *terraform.InstanceState, credentials string) (*terraform.InstanceState, []string, bool) {
+	ctx := context.Background()
+	r := resourceUserProvidedService()
+	diff, err := r.Diff(ctx, state, terraform.NewResourceConfigRaw(map[string]interface{}{
+		"name":               "fake-ups",
+		"space":              "space-guid",
+		"credentials":        map[string]interface{}{"password": credentials},
+		"restage_bound_apps": true,
+	}), f.session())
+	if err != nil {
+		t.Fatalf("diff failed: %s", err)
+	}
+	if diff == nil {
+		t.Fatalf("expected a diff to apply")
+	}
+	f.restaged = nil
+	newState, diags := r.Apply(ctx, state, diff, f.session())
+	restaged := f.restaged
+	sort.Strings(restaged)
+	return newState, restaged, diags.HasError()
+}
+
+func TestUserProvidedServiceUpdate_restageRetried(t *testing.T) {
+	cc := newFakeBoundAppsCC("app-1", "app-2")
+	defer cc.server.Close()
+
+	r := resourceUserProvidedService()
+	d := schema.TestResourceDataRaw(t, r.Schema, map[string]interface{}{
+		"name":               "fake-ups",
+		"space":              "space-guid",
+		"credentials":        map[string]interface{}{"password": "secret-1"},
+		"restage_bound_apps": true,
+	})
+	d.SetId("ups-guid")
+	state := d.State()
+
+	checkPending := func(state *terraform.InstanceState, expected ...string) {
+		found := make([]string, 0)
+		for k, v := range state.Attributes {
+			if strings.HasPrefix(k, "restage_pending_apps.") && k != "restage_pending_apps.#" {
+				found = append(found, v)
+			}
+		}
+		sort.Strings(found)
+		if strings.Join(found, ",") != strings.Join(expected, ",") {
+			t.Fatalf("expected apps %v to be pending but found %v", expected, found)
+		}
+	}
+
+	// credentials change, app-2 can't be restaged
+	cc.failing["app-2"] = true
+	state, restaged, failed := cc.apply(t, state, "secret-2")
+	if !failed || strings.Join(restaged, ",") != "app-1,app-2" {
+		t.Fatalf("expected restage of app-2 to fail, restaged apps: %v", restaged)
+	}
+	checkPending(state, "app-2")
+
+	// credentials change again while bound apps can't be listed, app-2 is still left to restage
+	cc.bindingsStatus = http.StatusBadGateway
+	state, restaged, failed = cc.apply(t, state, "secret-3")
+	if !failed || len(restaged) != 0 {
+		t.Fatalf("expected listing bound apps to fail, restaged apps: %v", restaged)
+	}
+	checkPending(state, "app-2")
+
+	// no change in config, only app-2 is restaged on retry
+	cc.bindingsStatus = 0
+	cc.failing["app-2"] = false
+	state, restaged, failed = cc.apply(t, state, "secret-3")
+	if failed || strings.Join(restaged, ",") != "app-2" {
+		t.Fatalf("expected only app-2 to be restaged again, restaged apps: %v", restaged)
+	}
+	checkPending(state)
+}
+
+func testAccCheckAppRecordedStrategy(resource, expected string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources[resource]
+		if !ok {
+			return fmt.Errorf("app '%s' not found in terraform state", resource)
+		}
+		strategy, err := appRecordedStrategy(testSession(), rs.Primary.ID)
+		if err != nil {
+			return err
+		}
+		if strategy != expected {
+			return fmt.Errorf("expected app recorded strategy '%s' but found '%s'", expected, strategy)
+		}
+		return nil
+	}
+}
+
+// testAccCheckAppDroplet - record current droplet of app in droplet, when mustChange is set,
+// app must have been restaged since droplet has been recorded
+func testAccCheckAppDroplet(resource string, droplet *string, mustChange bool) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources[resource]
+		if !ok {
+			return fmt.Errorf("app '%s' not found in terraform state", resource)
+		}
+		var current struct {
+			GUID string `json:"guid"`
+		}
+		_, err := v3Do(testSession(), "GET", fmt.Sprintf("/v3/apps/%s/droplets/current", rs.Primary.ID), nil, &current)
+		if err != nil {
+			return err
+		}
+		if mustChange && current.GUID == *droplet {
+			return fmt.Errorf("app '%s' has not been restaged, droplet is still '%s'", resource, current.GUID)
+		}
+		*droplet = current.GUID
+		return nil
+	}
+}
+
 func testAccCheckUserProvidedServiceExists(resource string) resource.TestCheckFunc {
 
 	return func(s *terraform.State) error {
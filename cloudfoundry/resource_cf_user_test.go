@@ -65,6 +65,31 @@ resource "cloudfoundry_user" "empty-group" {
 }
 `
 
+const userResourceOffboarding = `
+
+resource "cloudfoundry_user" "offboarded" {
+	name = "offboarded@acme.com"
+	password = "password"
+	phone_numbers = [ "+33 1 23 45 67 89" ]
+	password_change_required = true
+	deactivate_on_destroy = true
+}
+`
+
+const userResourceOffboardingUpdate = `
+
+resource "cloudfoundry_user" "offboarded" {
+	name = "offboarded@acme.com"
+	password = "password"
+	active = false
+	verified = false
+	phone_numbers = [ "+33 1 23 45 67 89", "+33 6 12 34 56 78" ]
+	password_change_required = true
+	lockout_reset = "1"
+	deactivate_on_destroy = true
+}
+`
+
 func TestAccResUser_LdapOrigin_normal(t *testing.T) {
 
 	ref := "cloudfoundry_user.manager1"
@@ -192,6 +217,43 @@ func TestAccResUser_EmptyGroups_normal(t *testing.T) {
 		})
 }
 
+func TestAccResUser_Offboarding_normal(t *testing.T) {
+
+	ref := "cloudfoundry_user.offboarded"
+	username := "offboarded@acme.com"
+
+	resource.Test(t,
+		resource.TestCase{
+			PreCheck:          func() { testAccPreCheck(t) },
+			ProviderFactories: testAccProvidersFactories,
+			CheckDestroy:      testAccCheckUserDeactivated(username),
+			Steps: []resource.TestStep{
+
+				resource.TestStep{
+					Config: userResourceOffboarding,
+					Check: resource.ComposeTestCheckFunc(
+						testAccCheckUserExists(ref),
+						resource.TestCheckResourceAttr(ref, "active", "true"),
+						resource.TestCheckResourceAttr(ref, "verified", "true"),
+						resource.TestCheckResourceAttr(ref, "phone_numbers.#", "1"),
+						resource.TestCheckResourceAttr(ref, "password_change_required", "true"),
+					),
+				},
+
+				resource.TestStep{
+					Config: userResourceOffboardingUpdate,
+					Check: resource.ComposeTestCheckFunc(
+						testAccCheckUserExists(ref),
+						resource.TestCheckResourceAttr(ref, "active", "false"),
+						resource.TestCheckResourceAttr(ref, "verified", "false"),
+						resource.TestCheckResourceAttr(ref, "phone_numbers.#", "2"),
+						resource.TestCheckTypeSetElemAttr(ref, "phone_numbers.*", "+33 6 12 34 56 78"),
+					),
+				},
+			},
+		})
+}
+
 func testAccCheckUserExists(resource string) resource.TestCheckFunc {
 
 	return func(s *terraform.State) error {
@@ -228,6 +290,18 @@ func testAccCheckUserExists(resource string) resource.TestCheckFunc {
 			return err
 		}
 
+		var details uaaUser
+		err = uaaDo(session, "GET", fmt.Sprintf("/Users/%s", id), nil, &details)
+		if err != nil {
+			return err
+		}
+		if err = assertEquals(attributes, "active", details.Active); err != nil {
+			return err
+		}
+		if err = assertEquals(attributes, "verified", details.Verified); err != nil {
+			return err
+		}
+
 		return err
 	}
 }
@@ -252,3 +326,24 @@ func testAccCheckUserDestroy(username string) resource.TestCheckFunc {
 		return nil
 	}
 }
+
+// testAccCheckUserDeactivated - check user is kept deactivated on destroy, then delete it
+func testAccCheckUserDeactivated(username string) resource.TestCheckFunc {
+
+	return func(s *terraform.State) error {
+		session := testAccProvider.Meta().(*managers.Session)
+		id, err := getUAAUserID(session, username, "uaa")
+		if err != nil {
+			return err
+		}
+		var user uaaUser
+		err = uaaDo(session, "GET", fmt.Sprintf("/Users/%s", id), nil, &user)
+		if err != nil {
+			return err
+		}
+		if user.Active {
+			return fmt.Errorf("user with username '%s' is still active", username)
+		}
+		return session.ClientUAA.DeleteUser(id)
+	}
+}
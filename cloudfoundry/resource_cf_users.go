@@ -0,0 +1,749 @@
+package cloudfoundry
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"github.com/terraform-providers/terraform-provider-cloudfoundry/cloudfoundry/managers"
+)
+
+// resourceUsers - many users with their org and space roles managed as one resource,
+// users are reconciled by batches to keep plans fast with thousands of users
+func resourceUsers() *schema.Resource {
+	types := make([]string, 0, len(roleTypes))
+	for t := range roleTypes {
+		types = append(types, t)
+	}
+
+	return &schema.Resource{
+		CreateContext: resourceUsersUpdate,
+		ReadContext:   resourceUsersRead,
+		UpdateContext: resourceUsersUpdate,
+		DeleteContext: resourceUsersDelete,
+
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(30 * time.Minute),
+			Update: schema.DefaultTimeout(30 * time.Minute),
+			Delete: schema.DefaultTimeout(30 * time.Minute),
+		},
+
+		Schema: map[string]*schema.Schema{
+			"user": &schema.Schema{
+				Type:     schema.TypeSet,
+				Optional: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"name": &schema.Schema{
+							Type:         schema.TypeString,
+							Required:     true,
+							ValidateFunc: validation.NoZeroValues,
+						},
+						"origin": &schema.Schema{
+							Type:     schema.TypeString,
+							Optional: true,
+							Default:  defaultUserOrigin,
+						},
+						"email": &schema.Schema{
+							Type:        schema.TypeString,
+							Optional:    true,
+							Description: "Email of user, default to name",
+						},
+						"given_name": &schema.Schema{
+							Type:     schema.TypeString,
+							Optional: true,
+						},
+						"family_name": &schema.Schema{
+							Type:     schema.TypeString,
+							Optional: true,
+						},
+						"role": &schema.Schema{
+							Type:     schema.TypeSet,
+							Optional: true,
+							Elem: &schema.Resource{
+								Schema: map[string]*schema.Schema{
+									"type": &schema.Schema{
+										Type:         schema.TypeString,
+										Required:     true,
+										ValidateFunc: validation.StringInSlice(types, false),
+									},
+									"org": &schema.Schema{
+										Type:     schema.TypeString,
+										Optional: true,
+									},
+									"space": &schema.Schema{
+										Type:     schema.TypeString,
+										Optional: true,
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			"batch_size": &schema.Schema{
+				Type:        schema.TypeInt,
+				Optional:    true,
+				Default:     100,
+				Description: "Number of users looked up in one request on uaa and cloud controller",
+				// users of a batch are given in query string, larger batches exceed request line limit of routers
+				ValidateFunc: validation.IntBetween(1, 100),
+			},
+			"concurrency": &schema.Schema{
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      8,
+				Description:  "Number of users or roles created or deleted at the same time",
+				ValidateFunc: validation.IntBetween(1, 50),
+			},
+			"deactivate_on_destroy": &schema.Schema{
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "Deactivate users instead of deleting them when they are removed or on destroy",
+			},
+			"user_ids": &schema.Schema{
+				Type:        schema.TypeMap,
+				Computed:    true,
+				Description: "User GUIDs by <origin>/<name>",
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+		},
+
+		CustomizeDiff: func(ctx context.Context, diff *schema.ResourceDiff, meta interface{}) error {
+			if !diff.NewValueKnown("user") {
+				return nil
+			}
+			seen := make(map[string]bool)
+			for _, u := range diff.Get("user").(*schema.Set).List() {
+				user := newBulkUser(u)
+				if seen[user.lookupKey()] {
+					return fmt.Errorf("user %s is given more than once", user.key())
+				}
+				seen[user.lookupKey()] = true
+				for _, r := range user.Roles {
+					if err := r.validate(); err != nil {
+						return fmt.Errorf("user %s: %s", user.key(), err)
+					}
+				}
+			}
+			return nil
+		},
+	}
+}
+
+// bulkUser - user as given in user block of cloudfoundry_users
+type bulkUser struct {
+	Name       string
+	Origin     string
+	Email      string
+	GivenName  string
+	FamilyName string
+	Roles      []bulkRole
+}
+
+// bulkRole - org or space role of a bulk user
+type bulkRole struct {
+	Type  string
+	Org   string
+	Space string
+}
+
+func newBulkUser(v interface{}) bulkUser {
+	m := v.(map[string]interface{})
+	user := bulkUser{
+		Name:       m["name"].(string),
+		Origin:     m["origin"].(string),
+		Email:      m["email"].(string),
+		GivenName:  m["given_name"].(string),
+		FamilyName: m["family_name"].(string),
+	}
+	if user.Origin == "" {
+		user.Origin = defaultUserOrigin
+	}
+	for _, r := range m["role"].(*schema.Set).List() {
+		role := r.(map[string]interface{})
+		user.Roles = append(user.Roles, bulkRole{
+			Type:  role["type"].(string),
+			Org:   role["org"].(string),
+			Space: role["space"].(string),
+		})
+	}
+	return user
+}
+
+func (u bulkUser) flatten() map[string]interface{} {
+	roles := make([]interface{}, len(u.Roles))
+	for i, r := range u.Roles {
+		roles[i] = map[string]interface{}{
+			"type":  r.Type,
+			"org":   r.Org,
+			"space": r.Space,
+		}
+	}
+	return map[string]interface{}{
+		"name":        u.Name,
+		"origin":      u.Origin,
+		"email":       u.Email,
+		"given_name":  u.GivenName,
+		"family_name": u.FamilyName,
+		"role":        roles,
+	}
+}
+
+// key - key of user in user_ids
+func (u bulkUser) key() string {
+	return computeID(u.Origin, u.Name)
+}
+
+// lookupKey - key matching user in uaa, usernames are case insensitive
+func (u bulkUser) lookupKey() string {
+	return computeID(u.Origin, strings.ToLower(u.Name))
+}
+
+func (u bulkUser) email() string {
+	if u.Email == "" {
+		return u.Name
+	}
+	return u.Email
+}
+
+func (r bulkRole) validate() error {
+	if err := validateRoleTarget(r.Type, r.Org, r.Space); err != nil {
+		return err
+	}
+	if r.target() == "" {
+		return fmt.Errorf("role %s has no %s set", r.Type, roleTypes[r.Type])
+	}
+	return nil
+}
+
+func (r bulkRole) target() string {
+	if roleTypes[r.Type] == "org" {
+		return r.Org
+	}
+	return r.Space
+}
+
+// roleKey - key of a role given to a user
+func roleKey(userGUID string, roleType string, target string) string {
+	return strings.Join([]string{userGUID, roleType, target}, "/")
+}
+
+// batches - split items in batches of at most size items
+func batches(items []string, size int) [][]string {
+	result := make([][]string, 0, len(items)/size+1)
+	for len(items) > size {
+		result = append(result, items[:size])
+		items = items[size:]
+	}
+	if len(items) > 0 {
+		result = append(result, items)
+	}
+	return result
+}
+
+// scimFilterOr - scim filter matching attr with any of values, json strings are valid scim filter strings
+func scimFilterOr(attr string, values []string) string {
+	conditions := make([]string, len(values))
+	for i, v := range values {
+		b, _ := json.Marshal(v)
+		conditions[i] = fmt.Sprintf("%s eq %s", attr, b)
+	}
+	return strings.Join(conditions, " or ")
+}
+
+// getUAAUsersByName - uaa users matching given users by <origin>/<lowercase name>
+func getUAAUsersByName(session *managers.Session, users []bulkUser, batchSize int) (map[string]uaaUser, error) {
+	namesByOrigin := make(map[string][]string)
+	for _, u := range users {
+		namesByOrigin[u.Origin] = append(namesByOrigin[u.Origin], u.Name)
+	}
+	found := make(map[string]uaaUser)
+	for origin, names := range namesByOrigin {
+		for _, batch := range batches(names, batchSize) {
+			o, _ := json.Marshal(origin)
+			filter := fmt.Sprintf("origin eq %s and (%s)", o, scimFilterOr("userName", batch))
+			err := getUAAUsers(session, filter, len(batch), func(u uaaUser) {
+				found[computeID(u.Origin, strings.ToLower(u.Username))] = u
+			})
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	return found, nil
+}
+
+// getUAAUsersByID - uaa users with given guids by guid
+func getUAAUsersByID(session *managers.Session, ids []string, batchSize int) (map[string]uaaUser, error) {
+	found := make(map[string]uaaUser)
+	for _, batch := range batches(ids, batchSize) {
+		err := getUAAUsers(session, scimFilterOr("id", batch), len(batch), func(u uaaUser) {
+			found[u.ID] = u
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return found, nil
+}
+
+func getUAAUsers(session *managers.Session, filter string, count int, f func(u uaaUser)) error {
+	query := url.Values{}
+	query.Set("filter", filter)
+	query.Set("count", strconv.Itoa(count))
+	var page struct {
+		Resources []uaaUser `json:"resources"`
+	}
+	err := uaaDo(session, "GET", "/Users?"+query.Encode(), nil, &page)
+	if err != nil {
+		return err
+	}
+	for _, u := range page.Resources {
+		f(u)
+	}
+	return nil
+}
+
+// getV3RolesOfUsers - roles of given users by role key
+func getV3RolesOfUsers(session *managers.Session, userGUIDs []string, batchSize int) (map[string]v3Role, error) {
+	roles := make(map[string]v3Role)
+	for _, batch := range batches(userGUIDs, batchSize) {
+		path := fmt.Sprintf("/v3/roles?per_page=5000&user_guids=%s", strings.Join(batch, ","))
+		err := v3GetAll(session, path, func(resources json.RawMessage) error {
+			var page []v3Role
+			err := json.Unmarshal(resources, &page)
+			if err != nil {
+				return err
+			}
+			for _, r := range page {
+				target := r.Relationships.Organization.GUID()
+				if target == "" {
+					target = r.Relationships.Space.GUID()
+				}
+				roles[roleKey(r.Relationships.User.GUID(), r.Type, target)] = r
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return roles, nil
+}
+
+func resourceUsersRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	session := meta.(*managers.Session)
+	batchSize := d.Get("batch_size").(int)
+
+	ids := d.Get("user_ids").(map[string]interface{})
+	guids := make([]string, 0, len(ids))
+	for _, id := range ids {
+		guids = append(guids, id.(string))
+	}
+	uaaUsers, err := getUAAUsersByID(session, guids, batchSize)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	roles, err := getV3RolesOfUsers(session, guids, batchSize)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	// users deleted outside of terraform are removed to be created again, only declared roles are checked
+	users := make([]interface{}, 0, len(ids))
+	newIDs := make(map[string]interface{})
+	for _, u := range d.Get("user").(*schema.Set).List() {
+		user := newBulkUser(u)
+		id, ok := ids[user.key()]
+		if !ok {
+			continue
+		}
+		uaaUser, ok := uaaUsers[id.(string)]
+		if !ok {
+			continue
+		}
+		if len(uaaUser.Emails) > 0 && uaaUser.Emails[0].Value != user.email() {
+			user.Email = uaaUser.Emails[0].Value
+		}
+		if user.GivenName != "" || user.FamilyName != "" {
+			user.GivenName = uaaUser.Name.GivenName
+			user.FamilyName = uaaUser.Name.FamilyName
+		}
+		declaredRoles := user.Roles
+		user.Roles = nil
+		for _, r := range declaredRoles {
+			if _, ok := roles[roleKey(uaaUser.ID, r.Type, r.target())]; ok {
+				user.Roles = append(user.Roles, r)
+			}
+		}
+		users = append(users, user.flatten())
+		newIDs[user.key()] = uaaUser.ID
+	}
+	d.Set("user", users)
+	d.Set("user_ids", newIDs)
+	return nil
+}
+
+func resourceUsersUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	session := meta.(*managers.Session)
+	batchSize := d.Get("batch_size").(int)
+	concurrency := d.Get("concurrency").(int)
+	timeout := d.Timeout(schema.TimeoutUpdate)
+	if d.IsNewResource() {
+		timeout = d.Timeout(schema.TimeoutCreate)
+	}
+
+	o, n := d.GetChange("user")
+	oldSet := o.(*schema.Set)
+	ids := make(map[string]string)
+	for k, v := range d.Get("user_ids").(map[string]interface{}) {
+		ids[k] = v.(string)
+	}
+	oldUsers := make(map[string]bulkUser)
+	for _, u := range oldSet.List() {
+		user := newBulkUser(u)
+		oldUsers[user.lookupKey()] = user
+	}
+
+	// only users added or changed since last apply are reconciled
+	changed := make([]bulkUser, 0)
+	newUsers := make(map[string]bulkUser)
+	for _, u := range n.(*schema.Set).List() {
+		user := newBulkUser(u)
+		newUsers[user.lookupKey()] = user
+		if _, known := ids[user.key()]; !known || !oldSet.Contains(u) {
+			changed = append(changed, user)
+		}
+	}
+	removed := make([]bulkUser, 0)
+	for k, user := range oldUsers {
+		if _, ok := newUsers[k]; !ok {
+			removed = append(removed, user)
+		}
+	}
+
+	diags := reconcileBulkUsers(ctx, session, changed, ids, batchSize, concurrency)
+	if diags.HasError() {
+		return append(diags, bulkUsersRollback(d, oldSet, ids)...)
+	}
+
+	// roles are reconciled from what terraform knows, roles given outside of terraform are kept
+	userGUIDs := make([]string, 0, len(changed)+len(removed))
+	for _, u := range append(changed, removed...) {
+		if id, ok := ids[u.key()]; ok {
+			userGUIDs = append(userGUIDs, id)
+		}
+	}
+	existing, err := getV3RolesOfUsers(session, userGUIDs, batchSize)
+	if err != nil {
+		return append(diag.FromErr(err), bulkUsersRollback(d, oldSet, ids)...)
+	}
+	toAdd := make(map[string]bulkRole)
+	toDelete := make(map[string]bulkRole)
+	for _, user := range append(changed, removed...) {
+		id := ids[user.key()]
+		wanted := make(map[string]bool)
+		for _, r := range newUsers[user.lookupKey()].Roles {
+			k := roleKey(id, r.Type, r.target())
+			wanted[k] = true
+			if _, ok := existing[k]; !ok {
+				toAdd[k] = r
+			}
+		}
+		for _, r := range oldUsers[user.lookupKey()].Roles {
+			k := roleKey(id, r.Type, r.target())
+			if _, ok := existing[k]; ok && !wanted[k] {
+				toDelete[k] = r
+			}
+		}
+	}
+	diags = append(diags, deleteBulkRoles(ctx, session, existing, toDelete, concurrency, timeout)...)
+	if !diags.HasError() {
+		diags = append(diags, createBulkRoles(ctx, session, toAdd, concurrency)...)
+	}
+	if !diags.HasError() {
+		diags = append(diags, removeBulkUsers(ctx, session, removed, ids, d.Get("deactivate_on_destroy").(bool), concurrency)...)
+	}
+	if diags.HasError() {
+		return append(diags, bulkUsersRollback(d, oldSet, ids)...)
+	}
+
+	userIDs := make(map[string]interface{})
+	for _, user := range newUsers {
+		userIDs[user.key()] = ids[user.key()]
+	}
+	d.Set("user_ids", userIDs)
+	if d.IsNewResource() {
+		d.SetId(fmt.Sprintf("users-%d", time.Now().UnixNano()))
+	}
+	return append(diags, resourceUsersRead(ctx, d, meta)...)
+}
+
+// bulkUsersRollback - keep users of last apply in state when reconciliation partially failed,
+// next apply takes over from there: users created meanwhile are found by name and existing roles are kept
+func bulkUsersRollback(d *schema.ResourceData, users *schema.Set, ids map[string]string) diag.Diagnostics {
+	userIDs := make(map[string]interface{})
+	for _, u := range users.List() {
+		user := newBulkUser(u)
+		if id, ok := ids[user.key()]; ok {
+			userIDs[user.key()] = id
+		}
+	}
+	d.Set("user", users)
+	d.Set("user_ids", userIDs)
+	return nil
+}
+
+// reconcileBulkUsers - create missing users in uaa and cloud controller, update details of changed ones.
+// ids is completed with guids of given users
+func reconcileBulkUsers(ctx context.Context, session *managers.Session, users []bulkUser, ids map[string]string, batchSize int, concurrency int) diag.Diagnostics {
+	if len(users) == 0 {
+		return nil
+	}
+	found, err := getUAAUsersByName(session, users, batchSize)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	var mutex sync.Mutex
+	byKey := make(map[string]bulkUser)
+	keys := make([]string, 0, len(users))
+	for _, u := range users {
+		byKey[u.lookupKey()] = u
+		keys = append(keys, u.lookupKey())
+	}
+	diags := doConcurrently(ctx, concurrency, keys, func(k string) error {
+		user := byKey[k]
+		details := map[string]interface{}{
+			"userName": user.Name,
+			"origin":   user.Origin,
+			"emails":   []map[string]interface{}{{"value": user.email(), "primary": true}},
+			"name": map[string]string{
+				"givenName":  user.GivenName,
+				"familyName": user.FamilyName,
+			},
+		}
+		// names are only given if user manages them, a user only given by name keeps its uaa names
+		if user.GivenName == "" && user.FamilyName == "" {
+			delete(details, "name")
+		}
+		existing, ok := found[k]
+		if !ok {
+			var created uaaUser
+			err := uaaDo(session, "POST", "/Users", details, &created)
+			if err != nil {
+				return fmt.Errorf("creating user %s in uaa failed: %s", user.key(), err)
+			}
+			existing = created
+		} else if bulkUserDetailsChanged(user, existing) {
+			err := uaaDo(session, "PATCH", fmt.Sprintf("/Users/%s", existing.ID), details, nil)
+			if err != nil {
+				return fmt.Errorf("updating user %s in uaa failed: %s", user.key(), err)
+			}
+		}
+		mutex.Lock()
+		defer mutex.Unlock()
+		ids[user.key()] = existing.ID
+		return nil
+	})
+	if diags.HasError() {
+		return diags
+	}
+
+	// users must be known by cloud controller before giving them roles
+	guids := make([]string, 0, len(users))
+	for _, u := range users {
+		guids = append(guids, ids[u.key()])
+	}
+	ccUsers := make(map[string]bool)
+	for _, batch := range batches(guids, batchSize) {
+		path := fmt.Sprintf("/v3/users?per_page=%d&guids=%s", batchSize, strings.Join(batch, ","))
+		err := v3GetAll(session, path, func(resources json.RawMessage) error {
+			var page []struct {
+				GUID string `json:"guid"`
+			}
+			err := json.Unmarshal(resources, &page)
+			if err != nil {
+				return err
+			}
+			for _, u := range page {
+				ccUsers[u.GUID] = true
+			}
+			return nil
+		})
+		if err != nil {
+			return append(diags, diag.FromErr(err)...)
+		}
+	}
+	missing := make([]string, 0)
+	for _, guid := range guids {
+		if !ccUsers[guid] {
+			missing = append(missing, guid)
+		}
+	}
+	return append(diags, doConcurrently(ctx, concurrency, missing, func(guid string) error {
+		_, err := v3Do(session, "POST", "/v3/users", map[string]string{"guid": guid}, nil)
+		if err != nil {
+			return fmt.Errorf("creating user %s in cloud controller failed: %s", guid, err)
+		}
+		return nil
+	})...)
+}
+
+func bulkUserDetailsChanged(user bulkUser, existing uaaUser) bool {
+	if len(existing.Emails) == 0 || existing.Emails[0].Value != user.email() {
+		return true
+	}
+	if user.GivenName == "" && user.FamilyName == "" {
+		return false
+	}
+	return existing.Name.GivenName != user.GivenName || existing.Name.FamilyName != user.FamilyName
+}
+
+// createBulkRoles - create roles by role key, org roles are created first as users must be in org to get a space role
+func createBulkRoles(ctx context.Context, session *managers.Session, roles map[string]bulkRole, concurrency int) diag.Diagnostics {
+	for _, kind := range []string{"org", "space"} {
+		keys := make([]string, 0)
+		for k, r := range roles {
+			if roleTypes[r.Type] == kind {
+				keys = append(keys, k)
+			}
+		}
+		diags := doConcurrently(ctx, concurrency, keys, func(k string) error {
+			r := roles[k]
+			userGUID := strings.SplitN(k, "/", 2)[0]
+			relationships := map[string]interface{}{
+				"user": newV3Relationship(userGUID),
+			}
+			if kind == "org" {
+				relationships["organization"] = newV3Relationship(r.Org)
+			} else {
+				relationships["space"] = newV3Relationship(r.Space)
+			}
+			_, err := v3Do(session, "POST", "/v3/roles", map[string]interface{}{
+				"type":          r.Type,
+				"relationships": relationships,
+			}, nil)
+			if err != nil {
+				return fmt.Errorf("giving role %s on %s to user %s failed: %s", r.Type, r.target(), userGUID, err)
+			}
+			return nil
+		})
+		if diags.HasError() {
+			return diags
+		}
+	}
+	return nil
+}
+
+// deleteBulkRoles - delete roles by role key, space roles are deleted first as org roles can't be removed while user has space roles
+func deleteBulkRoles(ctx context.Context, session *managers.Session, existing map[string]v3Role, roles map[string]bulkRole, concurrency int, timeout time.Duration) diag.Diagnostics {
+	var diags diag.Diagnostics
+	for _, kind := range []string{"space", "org"} {
+		keys := make([]string, 0)
+		for k, r := range roles {
+			if roleTypes[r.Type] == kind {
+				keys = append(keys, k)
+			}
+		}
+		diags = append(diags, doConcurrently(ctx, concurrency, keys, func(k string) error {
+			role := existing[k]
+			jobPath, err := v3Do(session, "DELETE", fmt.Sprintf("/v3/roles/%s", role.GUID), nil, nil)
+			if err != nil {
+				if IsErrNotFound(err) {
+					return nil
+				}
+				return fmt.Errorf("removing role %s from user %s failed: %s", role.Type, role.Relationships.User.GUID(), err)
+			}
+			if jobPath == "" {
+				return nil
+			}
+			_, err = v3PollJob(ctx, session, jobPath, timeout)
+			if err != nil {
+				return fmt.Errorf("removing role %s from user %s failed: %s", role.Type, role.Relationships.User.GUID(), err)
+			}
+			return nil
+		})...)
+		if diags.HasError() {
+			return diags
+		}
+	}
+	return diags
+}
+
+// removeBulkUsers - delete or deactivate users in uaa, removed users are taken out of ids
+func removeBulkUsers(ctx context.Context, session *managers.Session, users []bulkUser, ids map[string]string, deactivate bool, concurrency int) diag.Diagnostics {
+	var mutex sync.Mutex
+	byGUID := make(map[string]string)
+	guids := make([]string, 0, len(users))
+	for _, u := range users {
+		if id, ok := ids[u.key()]; ok {
+			byGUID[id] = u.key()
+			guids = append(guids, id)
+		}
+	}
+	return doConcurrently(ctx, concurrency, guids, func(guid string) error {
+		var err error
+		if deactivate {
+			err = uaaDo(session, "PATCH", fmt.Sprintf("/Users/%s", guid), map[string]bool{"active": false}, nil)
+		} else {
+			err = uaaDo(session, "DELETE", fmt.Sprintf("/Users/%s", guid), nil, nil)
+		}
+		if err != nil && !IsErrNotFound(err) {
+			return fmt.Errorf("removing user %s failed: %s", byGUID[guid], err)
+		}
+		mutex.Lock()
+		defer mutex.Unlock()
+		delete(ids, byGUID[guid])
+		return nil
+	})
+}
+
+func resourceUsersDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	session := meta.(*managers.Session)
+	batchSize := d.Get("batch_size").(int)
+	concurrency := d.Get("concurrency").(int)
+
+	ids := make(map[string]string)
+	for k, v := range d.Get("user_ids").(map[string]interface{}) {
+		ids[k] = v.(string)
+	}
+	users := make([]bulkUser, 0)
+	guids := make([]string, 0)
+	for _, u := range d.Get("user").(*schema.Set).List() {
+		user := newBulkUser(u)
+		if id, ok := ids[user.key()]; ok {
+			users = append(users, user)
+			guids = append(guids, id)
+		}
+	}
+
+	existing, err := getV3RolesOfUsers(session, guids, batchSize)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	toDelete := make(map[string]bulkRole)
+	for _, user := range users {
+		for _, r := range user.Roles {
+			k := roleKey(ids[user.key()], r.Type, r.target())
+			if _, ok := existing[k]; ok {
+				toDelete[k] = r
+			}
+		}
+	}
+	diags := deleteBulkRoles(ctx, session, existing, toDelete, concurrency, d.Timeout(schema.TimeoutDelete))
+	if diags.HasError() {
+		return diags
+	}
+	return append(diags, removeBulkUsers(ctx, session, users, ids, d.Get("deactivate_on_destroy").(bool), concurrency)...)
+}
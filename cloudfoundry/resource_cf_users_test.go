@@ -0,0 +1,227 @@
+package cloudfoundry
+
+import (
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+const usersResource = `
+locals {
+  developers = csvdecode(<<EOT
+name,email,space_role
+bulk-dev1@acme.com,,space_developer
+bulk-dev2@acme.com,dev2@acme.com,space_developer
+bulk-dev3@acme.com,,space_auditor
+EOT
+  )
+}
+
+resource "cloudfoundry_users" "developers" {
+  batch_size = 2
+  concurrency = 2
+
+  dynamic "user" {
+    for_each = local.developers
+    content {
+      name = user.value.name
+      email = user.value.email
+      role {
+        type = "organization_user"
+        org = "%[1]s"
+      }
+      role {
+        type = user.value.space_role
+        space = "%[2]s"
+      }
+    }
+  }
+}
+`
+
+const usersResourceUpdate = `
+locals {
+  developers = csvdecode(<<EOT
+name,email,space_role
+bulk-dev1@acme.com,,space_auditor
+bulk-dev2@acme.com,bulk-dev2@acme.com,space_developer
+EOT
+  )
+}
+
+resource "cloudfoundry_users" "developers" {
+  batch_size = 2
+  concurrency = 2
+
+  dynamic "user" {
+    for_each = local.developers
+    content {
+      name = user.value.name
+      email = user.value.email
+      role {
+        type = "organization_user"
+        org = "%[1]s"
+      }
+      role {
+        type = user.value.space_role
+        space = "%[2]s"
+      }
+    }
+  }
+}
+`
+
+const usersResourceInvalid = `
+resource "cloudfoundry_users" "invalid" {
+  user {
+    name = "bulk-invalid@acme.com"
+    role {
+      type = "space_developer"
+      org = "%s"
+    }
+  }
+}
+`
+
+func TestAccResUsers_normal(t *testing.T) {
+	orgID, _ := defaultTestOrg(t)
+	spaceID, _ := defaultTestSpace(t)
+	ref := "cloudfoundry_users.developers"
+
+	resource.Test(t,
+		resource.TestCase{
+			PreCheck:          func() { testAccPreCheck(t) },
+			ProviderFactories: testAccProvidersFactories,
+			CheckDestroy:      testAccCheckUsersDestroyed([]string{"bulk-dev1@acme.com", "bulk-dev2@acme.com", "bulk-dev3@acme.com"}),
+			Steps: []resource.TestStep{
+
+				resource.TestStep{
+					Config: fmt.Sprintf(usersResource, orgID, spaceID),
+					Check: resource.ComposeTestCheckFunc(
+						resource.TestCheckResourceAttr(ref, "user.#", "3"),
+						resource.TestCheckResourceAttr(ref, "user_ids.%", "3"),
+						resource.TestCheckTypeSetElemNestedAttrs(ref, "user.*", map[string]string{
+							"name":   "bulk-dev2@acme.com",
+							"email":  "dev2@acme.com",
+							"origin": "uaa",
+						}),
+						testAccCheckUsersRoles(ref, 6),
+					),
+				},
+
+				resource.TestStep{
+					Config: fmt.Sprintf(usersResourceUpdate, orgID, spaceID),
+					Check: resource.ComposeTestCheckFunc(
+						resource.TestCheckResourceAttr(ref, "user.#", "2"),
+						resource.TestCheckResourceAttr(ref, "user_ids.%", "2"),
+						resource.TestCheckNoResourceAttr(ref, "user_ids.uaa/bulk-dev3@acme.com"),
+						resource.TestCheckTypeSetElemNestedAttrs(ref, "user.*", map[string]string{
+							"name":  "bulk-dev2@acme.com",
+							"email": "bulk-dev2@acme.com",
+						}),
+						testAccCheckUsersRoles(ref, 4),
+						testAccCheckUsersDestroyed([]string{"bulk-dev3@acme.com"}),
+					),
+				},
+			},
+		})
+}
+
+func TestAccResUsers_invalidRole(t *testing.T) {
+	orgID, _ := defaultTestOrg(t)
+
+	resource.Test(t,
+		resource.TestCase{
+			PreCheck:          func() { testAccPreCheck(t) },
+			ProviderFactories: testAccProvidersFactories,
+			Steps: []resource.TestStep{
+				resource.TestStep{
+					Config:      fmt.Sprintf(usersResourceInvalid, orgID),
+					ExpectError: regexp.MustCompile(`role space_developer applies to a space`),
+				},
+			},
+		})
+}
+
+func TestBatches(t *testing.T) {
+	items := []string{"a", "b", "c", "d", "e"}
+	result := batches(items, 2)
+	if len(result) != 3 || len(result[0]) != 2 || len(result[2]) != 1 || result[2][0] != "e" {
+		t.Fatalf("unexpected batches: %v", result)
+	}
+	if result := batches(items[:4], 2); len(result) != 2 {
+		t.Fatalf("unexpected batches: %v", result)
+	}
+	if result := batches(nil, 2); len(result) != 0 {
+		t.Fatalf("unexpected batches: %v", result)
+	}
+}
+
+func TestScimFilterOr(t *testing.T) {
+	filter := scimFilterOr("userName", []string{"jdoe", `j"doe`})
+	expected := `userName eq "jdoe" or userName eq "j\"doe"`
+	if filter != expected {
+		t.Fatalf("expected filter '%s' but found '%s'", expected, filter)
+	}
+}
+
+func TestBulkRoleValidate(t *testing.T) {
+	for _, c := range []struct {
+		role     bulkRole
+		expected string
+	}{
+		{bulkRole{Type: "organization_manager", Org: "org-guid"}, ""},
+		{bulkRole{Type: "space_developer", Space: "space-guid"}, ""},
+		{bulkRole{Type: "organization_manager", Space: "space-guid"}, "role organization_manager applies to an org, org must be set instead of space"},
+		{bulkRole{Type: "space_developer", Org: "org-guid"}, "role space_developer applies to a space, space must be set instead of org"},
+		{bulkRole{Type: "space_developer"}, "role space_developer has no space set"},
+	} {
+		err := c.role.validate()
+		if (err == nil && c.expected != "") || (err != nil && err.Error() != c.expected) {
+			t.Fatalf("expected error '%s' for role %v but found '%v'", c.expected, c.role, err)
+		}
+	}
+}
+
+func testAccCheckUsersRoles(resource string, expected int) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources[resource]
+		if !ok {
+			return fmt.Errorf("users '%s' not found in terraform state", resource)
+		}
+		guids := make([]string, 0)
+		for k, v := range rs.Primary.Attributes {
+			if regexp.MustCompile(`^user_ids\.[^%]`).MatchString(k) {
+				guids = append(guids, v)
+			}
+		}
+		roles, err := getV3RolesOfUsers(testSession(), guids, 100)
+		if err != nil {
+			return err
+		}
+		if len(roles) != expected {
+			return fmt.Errorf("expected %d roles given to users but found %d", expected, len(roles))
+		}
+		return nil
+	}
+}
+
+func testAccCheckUsersDestroyed(usernames []string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		users := make([]bulkUser, len(usernames))
+		for i, name := range usernames {
+			users[i] = bulkUser{Name: name, Origin: defaultUserOrigin}
+		}
+		found, err := getUAAUsersByName(testSession(), users, 100)
+		if err != nil {
+			return err
+		}
+		if len(found) > 0 {
+			return fmt.Errorf("expected users %v to be deleted but %d still exist in uaa", usernames, len(found))
+		}
+		return nil
+	}
+}
@@ -197,3 +197,7 @@ func AppDeployToResourceData(d *schema.ResourceData, appDeploy appdeployers.AppD
 type ResourceChanger interface {
 	HasChange(key string) bool
 }
+
+type ResourceGetter interface {
+	Get(key string) interface{}
+}
@@ -0,0 +1,115 @@
+package cloudfoundry
+
+import (
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"github.com/terraform-providers/terraform-provider-cloudfoundry/cloudfoundry/managers/bits"
+)
+
+const (
+	gitKey       = "git"
+	gitCommitKey = "git_commit"
+)
+
+func gitSchema(conflictsWith ...string) *schema.Schema {
+	return &schema.Schema{
+		Type:          schema.TypeList,
+		Optional:      true,
+		MaxItems:      1,
+		Description:   "Git repository to clone and zip as bits",
+		ConflictsWith: conflictsWith,
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"url": &schema.Schema{
+					Type:         schema.TypeString,
+					Required:     true,
+					ValidateFunc: validation.NoZeroValues,
+				},
+				"ref": &schema.Schema{
+					Type:        schema.TypeString,
+					Optional:    true,
+					Description: "Branch, tag or commit sha to checkout, default to remote HEAD",
+				},
+				"subdirectory": &schema.Schema{
+					Type:        schema.TypeString,
+					Optional:    true,
+					Description: "Subdirectory of the repository to use as root",
+				},
+				"ssh_key_file": &schema.Schema{
+					Type:        schema.TypeString,
+					Optional:    true,
+					Description: "Path to a private key file used to clone over ssh",
+				},
+			},
+		},
+	}
+}
+
+func gitCommitSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:        schema.TypeString,
+		Computed:    true,
+		Description: "Commit sha resolved from git ref at last deployment",
+	}
+}
+
+func resourceDataToGitSource(d ResourceGetter) (bits.GitSource, bool) {
+	gits := getListOfStructs(d.Get(gitKey))
+	if len(gits) == 0 {
+		return bits.GitSource{}, false
+	}
+	git := gits[0]
+	return bits.GitSource{
+		URL:          git["url"].(string),
+		Ref:          git["ref"].(string),
+		Subdirectory: git["subdirectory"].(string),
+		SSHKeyFile:   git["ssh_key_file"].(string),
+	}, true
+}
+
+// gitBitsPrepare - Clone git source, if any, into a zip and give back path to this zip.
+// Returned cleanup function must be called when zip is no longer needed.
+func gitBitsPrepare(d *schema.ResourceData) (path string, commit string, cleanup func(), err error) {
+	cleanup = func() {}
+	src, ok := resourceDataToGitSource(d)
+	if !ok {
+		return d.Get("path").(string), "", cleanup, nil
+	}
+	log.Printf("[INFO] Cloning git repository %s at ref '%s'", src.URL, src.Ref)
+	path, commit, err = bits.ZipGitToFile(src)
+	if err != nil {
+		return "", "", cleanup, err
+	}
+	cleanup = func() {
+		os.RemoveAll(filepath.Dir(path))
+	}
+	return path, commit, cleanup, nil
+}
+
+// gitCommitCustomizeDiff - Resolve git ref on remote and mark commit as changed
+// when ref now points to a different commit than the one deployed (e.g. a branch has new commits)
+func gitCommitCustomizeDiff(diff *schema.ResourceDiff) error {
+	src, ok := resourceDataToGitSource(diff)
+	if !ok {
+		if diff.Get(gitCommitKey).(string) != "" {
+			return diff.SetNew(gitCommitKey, "")
+		}
+		return nil
+	}
+	if diff.HasChange(gitKey) {
+		return diff.SetNewComputed(gitCommitKey)
+	}
+	commit, err := bits.ResolveGitRef(src)
+	if err != nil {
+		log.Printf("[WARN] Could not resolve git ref '%s' on %s, skipping drift detection: %s", src.Ref, src.URL, err.Error())
+		return nil
+	}
+	if commit == "" || commit == diff.Get(gitCommitKey).(string) {
+		return nil
+	}
+	return diff.SetNew(gitCommitKey, commit)
+}
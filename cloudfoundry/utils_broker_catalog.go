@@ -0,0 +1,214 @@
+package cloudfoundry
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// maxCatalogSchemaSize - size limit of a plan schema applied by cloud controller
+const maxCatalogSchemaSize = 64 * 1024
+
+// osbCatalog - catalog as given by a broker on /v2/catalog, only what is validated is decoded
+type osbCatalog struct {
+	Services *[]struct {
+		ID          string `json:"id"`
+		Name        string `json:"name"`
+		Description string `json:"description"`
+		Plans       []struct {
+			ID          string `json:"id"`
+			Name        string `json:"name"`
+			Description string `json:"description"`
+			Schemas     map[string]map[string]struct {
+				Parameters json.RawMessage `json:"parameters"`
+			} `json:"schemas"`
+		} `json:"plans"`
+	} `json:"services"`
+}
+
+// validateBrokerCatalog - check a broker catalog against open service broker api rules enforced by cloud controller,
+// all violations are given back with their path inside catalog
+func validateBrokerCatalog(raw []byte) []string {
+	var catalog osbCatalog
+	err := json.Unmarshal(raw, &catalog)
+	if err != nil {
+		return []string{fmt.Sprintf("catalog is not a valid json object: %s", err)}
+	}
+	if catalog.Services == nil || len(*catalog.Services) == 0 {
+		return []string{"services: broker must provide at least one service"}
+	}
+
+	violations := make([]string, 0)
+	violate := func(path string, format string, a ...interface{}) {
+		violations = append(violations, fmt.Sprintf("%s: %s", path, fmt.Sprintf(format, a...)))
+	}
+	required := func(path, field, value string) {
+		if value == "" {
+			violate(path+"."+field, "is required")
+		}
+	}
+
+	serviceIDs := make(map[string]string)
+	serviceNames := make(map[string]string)
+	planIDs := make(map[string]string)
+	for i, service := range *catalog.Services {
+		servicePath := fmt.Sprintf("services[%d]", i)
+		required(servicePath, "id", service.ID)
+		required(servicePath, "name", service.Name)
+		required(servicePath, "description", service.Description)
+		if previous, ok := serviceIDs[service.ID]; ok && service.ID != "" {
+			violate(servicePath+".id", "id %q is already used by %s", service.ID, previous)
+		}
+		serviceIDs[service.ID] = servicePath
+		if previous, ok := serviceNames[service.Name]; ok && service.Name != "" {
+			violate(servicePath+".name", "name %q is already used by %s", service.Name, previous)
+		}
+		serviceNames[service.Name] = servicePath
+
+		if len(service.Plans) == 0 {
+			violate(servicePath+".plans", "service %q must provide at least one plan", service.Name)
+		}
+		planNames := make(map[string]string)
+		for j, plan := range service.Plans {
+			planPath := fmt.Sprintf("%s.plans[%d]", servicePath, j)
+			required(planPath, "id", plan.ID)
+			required(planPath, "name", plan.Name)
+			required(planPath, "description", plan.Description)
+			if previous, ok := planIDs[plan.ID]; ok && plan.ID != "" {
+				violate(planPath+".id", "id %q is already used by %s", plan.ID, previous)
+			}
+			planIDs[plan.ID] = planPath
+			if previous, ok := planNames[plan.Name]; ok && plan.Name != "" {
+				violate(planPath+".name", "name %q is already used by %s in service %q", plan.Name, previous, service.Name)
+			}
+			planNames[plan.Name] = planPath
+
+			violations = append(violations, validateCatalogPlanSchemas(planPath+".schemas", plan.Schemas)...)
+		}
+	}
+	return violations
+}
+
+func validateCatalogPlanSchemas(path string, schemas map[string]map[string]struct {
+	Parameters json.RawMessage `json:"parameters"`
+}) []string {
+	violations := make([]string, 0)
+	allowed := map[string][]string{
+		"service_instance": {"create", "update"},
+		"service_binding":  {"create"},
+	}
+	kinds := make([]string, 0, len(schemas))
+	for kind := range schemas {
+		kinds = append(kinds, kind)
+	}
+	sort.Strings(kinds)
+	for _, kind := range kinds {
+		actions, ok := allowed[kind]
+		if !ok {
+			violations = append(violations, fmt.Sprintf("%s.%s: unknown schema kind, must be one of service_instance or service_binding", path, kind))
+			continue
+		}
+		for _, action := range actions {
+			s, ok := schemas[kind][action]
+			if !ok || len(s.Parameters) == 0 {
+				continue
+			}
+			violations = append(violations, validateCatalogSchema(fmt.Sprintf("%s.%s.%s.parameters", path, kind, action), s.Parameters)...)
+		}
+	}
+	return violations
+}
+
+// validateCatalogSchema - check a json schema given in a plan, as cloud controller does
+func validateCatalogSchema(path string, raw json.RawMessage) []string {
+	if len(raw) > maxCatalogSchemaSize {
+		return []string{fmt.Sprintf("%s: schema must not be larger than %d bytes", path, maxCatalogSchemaSize)}
+	}
+	var s map[string]interface{}
+	err := json.Unmarshal(raw, &s)
+	if err != nil {
+		return []string{fmt.Sprintf("%s: schema must be a json object", path)}
+	}
+	violations := make([]string, 0)
+	if _, ok := s["$schema"].(string); !ok {
+		violations = append(violations, fmt.Sprintf("%s: schema must have a $schema key", path))
+	}
+	if t, ok := s["type"]; !ok || t != "object" {
+		violations = append(violations, fmt.Sprintf("%s: schema must have field \"type\" with value \"object\"", path))
+	}
+	return append(violations, jsonSchemaCheck(s, path)...)
+}
+
+// jsonSchemaCheck - check that keywords of a json schema are well formed, this is the subset used by jsonSchemaValidate
+func jsonSchemaCheck(s map[string]interface{}, path string) []string {
+	violations := make([]string, 0)
+	validTypes := map[string]bool{
+		"null": true, "boolean": true, "object": true, "array": true, "number": true, "integer": true, "string": true,
+	}
+	if t, ok := s["type"]; ok {
+		types := jsonSchemaTypes(t)
+		if len(types) == 0 {
+			violations = append(violations, fmt.Sprintf("%s.type: must be a string or an array of strings", path))
+		}
+		for _, tt := range types {
+			if !validTypes[tt] {
+				violations = append(violations, fmt.Sprintf("%s.type: unknown type %q", path, tt))
+			}
+		}
+	}
+	if r, ok := s["required"]; ok {
+		list, isList := r.([]interface{})
+		for _, e := range list {
+			if _, isString := e.(string); !isString {
+				isList = false
+			}
+		}
+		if !isList {
+			violations = append(violations, fmt.Sprintf("%s.required: must be an array of strings", path))
+		}
+	}
+	if e, ok := s["enum"]; ok {
+		if list, isList := e.([]interface{}); !isList || len(list) == 0 {
+			violations = append(violations, fmt.Sprintf("%s.enum: must be a non empty array", path))
+		}
+	}
+	for _, keyword := range []string{"minimum", "maximum", "minLength", "maxLength", "minItems", "maxItems"} {
+		if v, ok := s[keyword]; ok {
+			if _, isNumber := v.(float64); !isNumber {
+				violations = append(violations, fmt.Sprintf("%s.%s: must be a number", path, keyword))
+			}
+		}
+	}
+	if p, ok := s["properties"]; ok {
+		props, isMap := p.(map[string]interface{})
+		if !isMap {
+			violations = append(violations, fmt.Sprintf("%s.properties: must be an object", path))
+		}
+		names := make([]string, 0, len(props))
+		for name := range props {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			sub, isMap := props[name].(map[string]interface{})
+			if !isMap {
+				violations = append(violations, fmt.Sprintf("%s.properties.%s: must be a schema object", path, name))
+				continue
+			}
+			violations = append(violations, jsonSchemaCheck(sub, fmt.Sprintf("%s.properties.%s", path, name))...)
+		}
+	}
+	if i, ok := s["items"]; ok {
+		if sub, isMap := i.(map[string]interface{}); isMap {
+			violations = append(violations, jsonSchemaCheck(sub, path+".items")...)
+		}
+	}
+	return violations
+}
+
+// brokerCatalogError - one error listing all violations of a catalog
+func brokerCatalogError(brokerUrl string, violations []string) error {
+	return fmt.Errorf("catalog of service broker %s does not follow open service broker api:\n  - %s",
+		brokerUrl, strings.Join(violations, "\n  - "))
+}
@@ -0,0 +1,72 @@
+package cloudfoundry
+
+import (
+	"strings"
+	"testing"
+)
+
+const brokerCatalogInvalid = `{
+  "services": [
+    {
+      "id": "svc-1",
+      "name": "mysql",
+      "description": "MySQL",
+      "plans": [
+        { "id": "plan-1", "name": "small", "description": "small" },
+        { "id": "plan-2", "name": "small", "description": "small again" }
+      ]
+    },
+    {
+      "id": "svc-1",
+      "name": "redis",
+      "description": "Redis",
+      "plans": [
+        {
+          "id": "plan-1",
+          "name": "cache",
+          "description": "cache",
+          "schemas": {
+            "service_instance": {
+              "create": {
+                "parameters": {
+                  "type": "object",
+                  "properties": { "size": { "type": "text" } }
+                }
+              }
+            }
+          }
+        }
+      ]
+    },
+    { "id": "svc-3", "name": "empty", "description": "no plan", "plans": [] }
+  ]
+}`
+
+func TestValidateBrokerCatalog(t *testing.T) {
+	violations := validateBrokerCatalog([]byte(brokerCatalogInvalid))
+	expected := []string{
+		`services[0].plans[1].name: name "small" is already used by services[0].plans[0] in service "mysql"`,
+		`services[1].id: id "svc-1" is already used by services[0]`,
+		`services[1].plans[0].id: id "plan-1" is already used by services[0].plans[0]`,
+		`services[1].plans[0].schemas.service_instance.create.parameters: schema must have a $schema key`,
+		`services[1].plans[0].schemas.service_instance.create.parameters.properties.size.type: unknown type "text"`,
+		`services[2].plans: service "empty" must provide at least one plan`,
+	}
+	if len(violations) != len(expected) {
+		t.Errorf("expected %d violations but got %d:\n%s", len(expected), len(violations), strings.Join(violations, "\n"))
+	}
+	all := strings.Join(violations, "\n")
+	for _, e := range expected {
+		if !strings.Contains(all, e) {
+			t.Errorf("expected violation %q not found in:\n%s", e, all)
+		}
+	}
+
+	valid := `{"services": [{"id": "a", "name": "a", "description": "a", "plans": [{"id": "b", "name": "b", "description": "b"}]}]}`
+	if violations := validateBrokerCatalog([]byte(valid)); len(violations) > 0 {
+		t.Errorf("expected valid catalog but got:\n%s", strings.Join(violations, "\n"))
+	}
+	if violations := validateBrokerCatalog([]byte(`{"services": []}`)); len(violations) != 1 {
+		t.Errorf("expected catalog without services to be invalid")
+	}
+}
@@ -0,0 +1,39 @@
+package cloudfoundry
+
+import (
+	"context"
+	"sync"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+)
+
+// doConcurrently - call f on each item with at most concurrency calls at the same time, all failures are given back
+func doConcurrently(ctx context.Context, concurrency int, items []string, f func(item string) error) diag.Diagnostics {
+	var diags diag.Diagnostics
+	var mutex sync.Mutex
+	var wg sync.WaitGroup
+	slots := make(chan struct{}, concurrency)
+	for _, item := range items {
+		wg.Add(1)
+		go func(item string) {
+			defer wg.Done()
+			select {
+			case slots <- struct{}{}:
+			case <-ctx.Done():
+				return
+			}
+			defer func() { <-slots }()
+			err := f(item)
+			if err != nil {
+				mutex.Lock()
+				defer mutex.Unlock()
+				diags = append(diags, diag.FromErr(err)...)
+			}
+		}(item)
+	}
+	wg.Wait()
+	if ctx.Err() != nil {
+		diags = append(diags, diag.FromErr(ctx.Err())...)
+	}
+	return diags
+}
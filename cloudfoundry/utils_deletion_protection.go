@@ -0,0 +1,115 @@
+package cloudfoundry
+
+import (
+	"fmt"
+	"strings"
+
+	"code.cloudfoundry.org/cli/api/cloudcontroller/ccv2"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/terraform-providers/terraform-provider-cloudfoundry/cloudfoundry/managers"
+)
+
+// deletionInventoryLimit - number of resources of each kind named in inventory of a container refused to be deleted
+const deletionInventoryLimit = 10
+
+// deletionProtectionSchema - refuse to delete an org or a space holding resources
+func deletionProtectionSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:        schema.TypeBool,
+		Optional:    true,
+		Default:     true,
+		Description: "Refuse to delete when apps, service instances or routes are inside, unless delete_recursive is set",
+	}
+}
+
+// deleteRecursiveSchema - delete an org or a space with all resources inside
+func deleteRecursiveSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:        schema.TypeBool,
+		Optional:    true,
+		Default:     false,
+		Description: "Delete apps, service instances and routes inside on destroy, cloud controller refuses to delete a container which is not empty otherwise",
+	}
+}
+
+// deletionProtectionRead - set deletion protection attributes missing from state (e.g. import or state made by an older provider)
+// to their defaults, resource would be left unprotected on destroy otherwise
+func deletionProtectionRead(d *schema.ResourceData) {
+	if _, ok := d.GetOkExists("deletion_protection"); !ok {
+		d.Set("deletion_protection", true)
+	}
+	if _, ok := d.GetOkExists("delete_recursive"); !ok {
+		d.Set("delete_recursive", false)
+	}
+}
+
+// deletionProtectionCheck - error with inventory of resources in org or space when it is protected and not empty.
+// filter is the v3 list filter on the container, e.g. space_guids=<guid>
+func deletionProtectionCheck(session *managers.Session, d *schema.ResourceData, kind string, filter string) error {
+	protected, ok := d.GetOkExists("deletion_protection")
+	if (ok && !protected.(bool)) || d.Get("delete_recursive").(bool) {
+		return nil
+	}
+
+	inventory := make([]string, 0)
+	for _, r := range []struct {
+		title     string
+		path      string
+		nameField string
+	}{
+		{"apps", "/v3/apps", "name"},
+		{"service instances", "/v3/service_instances", "name"},
+		{"routes", "/v3/routes", "url"},
+	} {
+		names, total, err := v3ListNames(session, fmt.Sprintf("%s?per_page=%d&%s", r.path, deletionInventoryLimit, filter), r.nameField)
+		if err != nil {
+			return err
+		}
+		if total == 0 {
+			continue
+		}
+		line := fmt.Sprintf("  %s (%d): %s", r.title, total, strings.Join(names, ", "))
+		if total > len(names) {
+			line += fmt.Sprintf(" and %d more", total-len(names))
+		}
+		inventory = append(inventory, line)
+	}
+	if len(inventory) == 0 {
+		return nil
+	}
+	return fmt.Errorf(
+		"%s '%s' is not empty and is protected from deletion, it holds:\n%s\nSet delete_recursive to true to delete it with everything inside",
+		kind, d.Get("name").(string), strings.Join(inventory, "\n"),
+	)
+}
+
+// deleteContainer - delete org or space at path (e.g. /v2/spaces/<guid>) and wait for its job,
+// deletion is only recursive when delete_recursive is set, cloud controller refuses to delete it when not empty otherwise
+func deleteContainer(session *managers.Session, d *schema.ResourceData, path string) error {
+	var job ccv2.Job
+	_, err := v3Do(session, "DELETE", fmt.Sprintf("%s?recursive=%t&async=true", path, d.Get("delete_recursive").(bool)), nil, &job)
+	if err != nil {
+		return err
+	}
+	_, err = session.ClientV2.PollJob(job)
+	return err
+}
+
+// v3ListNames - names given by field of resources on first page of a v3 list endpoint with total number of resources
+func v3ListNames(session *managers.Session, path string, field string) ([]string, int, error) {
+	var page struct {
+		Pagination struct {
+			TotalResults int `json:"total_results"`
+		} `json:"pagination"`
+		Resources []map[string]interface{} `json:"resources"`
+	}
+	_, err := v3Do(session, "GET", path, nil, &page)
+	if err != nil {
+		return nil, 0, err
+	}
+	names := make([]string, 0, len(page.Resources))
+	for _, r := range page.Resources {
+		names = append(names, fmt.Sprint(r[field]))
+	}
+	return names, page.Pagination.TotalResults, nil
+}
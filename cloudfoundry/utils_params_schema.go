@@ -0,0 +1,335 @@
+package cloudfoundry
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/terraform-providers/terraform-provider-cloudfoundry/cloudfoundry/managers"
+)
+
+// v3ServicePlanSchemas - json schemas of parameters published by broker for a service plan
+type v3ServicePlanSchemas struct {
+	ServiceInstance struct {
+		Create struct {
+			Parameters map[string]interface{} `json:"parameters"`
+		} `json:"create"`
+		Update struct {
+			Parameters map[string]interface{} `json:"parameters"`
+		} `json:"update"`
+	} `json:"service_instance"`
+	ServiceBinding struct {
+		Create struct {
+			Parameters map[string]interface{} `json:"parameters"`
+		} `json:"create"`
+	} `json:"service_binding"`
+}
+
+// getServicePlanSchemas - retrieve schemas of a plan, plan not visible anymore gives back no schema
+func getServicePlanSchemas(session *managers.Session, planGUID string) (v3ServicePlanSchemas, error) {
+	var plan struct {
+		Schemas v3ServicePlanSchemas `json:"schemas"`
+	}
+	_, err := v3Do(session, "GET", fmt.Sprintf("/v3/service_plans/%s", planGUID), nil, &plan)
+	if err != nil && (IsErrNotFound(err) || IsErrNotAuthorized(err)) {
+		return v3ServicePlanSchemas{}, nil
+	}
+	return plan.Schemas, err
+}
+
+// getServiceInstanceBindingSchema - retrieve binding schema of the plan of a service instance,
+// user provided service instance has no plan and so no schema
+func getServiceInstanceBindingSchema(session *managers.Session, serviceInstanceGUID string) (map[string]interface{}, error) {
+	si, err := getV3ServiceInstance(session, serviceInstanceGUID)
+	if err != nil {
+		if IsErrNotFound(err) || IsErrNotAuthorized(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	planGUID := si.Relationships.ServicePlan.GUID()
+	if planGUID == "" {
+		return nil, nil
+	}
+	schemas, err := getServicePlanSchemas(session, planGUID)
+	if err != nil {
+		return nil, err
+	}
+	return schemas.ServiceBinding.Create.Parameters, nil
+}
+
+// bindingParamsCustomizeDiff - validate params given as a map in attribute mapKey or as json in attribute jsonKey
+// against binding schema of service instance found in attribute siKey, nothing is checked when one of them is not known yet.
+// mapKey is empty when params can only be given as json
+func bindingParamsCustomizeDiff(diff *schema.ResourceDiff, session *managers.Session, siKey, mapKey, jsonKey string) error {
+	if !diff.NewValueKnown(siKey) {
+		return nil
+	}
+	key, params, err := bindingParams(diff, mapKey, jsonKey)
+	if err != nil || params == "" {
+		return err
+	}
+	paramsSchema, err := getServiceInstanceBindingSchema(session, diff.Get(siKey).(string))
+	if err != nil {
+		return err
+	}
+	return validateParamsSchema(key, params, paramsSchema)
+}
+
+// bindingParams - params as json string with the attribute they are given by, map takes precedence over json
+// as it does when binding is created. Params are empty when they are not set or not known yet
+func bindingParams(diff *schema.ResourceDiff, mapKey, jsonKey string) (string, string, error) {
+	if mapKey != "" {
+		if !diff.NewValueKnown(mapKey) {
+			return "", "", nil
+		}
+		if params := diff.Get(mapKey).(map[string]interface{}); len(params) > 0 {
+			b, err := json.Marshal(params)
+			if err != nil {
+				return "", "", err
+			}
+			return mapKey, string(b), nil
+		}
+	}
+	if !diff.NewValueKnown(jsonKey) {
+		return "", "", nil
+	}
+	return jsonKey, diff.Get(jsonKey).(string), nil
+}
+
+// validateParamsSchema - validate params given as json string against a json schema published by a broker,
+// all violations are given back in one error which points at attribute key and path inside params
+func validateParamsSchema(key string, params string, paramsSchema map[string]interface{}) error {
+	if len(paramsSchema) == 0 || params == "" {
+		return nil
+	}
+	var value interface{}
+	err := json.Unmarshal([]byte(params), &value)
+	if err != nil {
+		return fmt.Errorf("%s: %s", key, err.Error())
+	}
+	violations := jsonSchemaValidate(paramsSchema, value, key)
+	if len(violations) == 0 {
+		return nil
+	}
+	return fmt.Errorf("%s does not match schema published by service broker:\n  - %s",
+		key, strings.Join(violations, "\n  - "))
+}
+
+// jsonSchemaValidate - validate a decoded json value against a json schema, violations are prefixed by path of value.
+// This is the subset of json schema (draft 04 to 07) used by brokers to describe params, $ref is not followed.
+func jsonSchemaValidate(s map[string]interface{}, value interface{}, path string) []string {
+	violations := make([]string, 0)
+	violate := func(format string, a ...interface{}) {
+		violations = append(violations, fmt.Sprintf("%s: %s", path, fmt.Sprintf(format, a...)))
+	}
+
+	if types := jsonSchemaTypes(s["type"]); len(types) > 0 {
+		found := false
+		for _, t := range types {
+			if jsonSchemaIsType(value, t) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			violate("must be of type %s but is %s", strings.Join(types, " or "), jsonSchemaTypeOf(value))
+			return violations
+		}
+	}
+	if enum, ok := s["enum"].([]interface{}); ok {
+		found := false
+		for _, e := range enum {
+			if reflect.DeepEqual(e, value) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			b, _ := json.Marshal(enum)
+			violate("must be one of %s", string(b))
+		}
+	}
+	if c, ok := s["const"]; ok && !reflect.DeepEqual(c, value) {
+		b, _ := json.Marshal(c)
+		violate("must be %s", string(b))
+	}
+
+	for _, keyword := range []string{"allOf", "anyOf", "oneOf"} {
+		subs, ok := s[keyword].([]interface{})
+		if !ok {
+			continue
+		}
+		matched := 0
+		for _, sub := range subs {
+			subSchema, ok := sub.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			subViolations := jsonSchemaValidate(subSchema, value, path)
+			if keyword == "allOf" {
+				violations = append(violations, subViolations...)
+			}
+			if len(subViolations) == 0 {
+				matched++
+			}
+		}
+		if keyword == "anyOf" && matched == 0 {
+			violate("must match at least one schema of anyOf")
+		}
+		if keyword == "oneOf" && matched != 1 {
+			violate("must match exactly one schema of oneOf but matches %d", matched)
+		}
+	}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		violations = append(violations, jsonSchemaValidateObject(s, v, path)...)
+	case []interface{}:
+		if min, ok := s["minItems"].(float64); ok && float64(len(v)) < min {
+			violate("must have at least %v items", min)
+		}
+		if max, ok := s["maxItems"].(float64); ok && float64(len(v)) > max {
+			violate("must have at most %v items", max)
+		}
+		if items, ok := s["items"].(map[string]interface{}); ok {
+			for i, item := range v {
+				violations = append(violations, jsonSchemaValidate(items, item, fmt.Sprintf("%s[%d]", path, i))...)
+			}
+		}
+	case string:
+		length := float64(len([]rune(v)))
+		if min, ok := s["minLength"].(float64); ok && length < min {
+			violate("must be at least %v characters long", min)
+		}
+		if max, ok := s["maxLength"].(float64); ok && length > max {
+			violate("must be at most %v characters long", max)
+		}
+		if pattern, ok := s["pattern"].(string); ok {
+			// invalid pattern is the broker fault, it is left to the broker to complain
+			if re, err := regexp.Compile(pattern); err == nil && !re.MatchString(v) {
+				violate("must match pattern %q", pattern)
+			}
+		}
+	case float64:
+		if min, ok := s["minimum"].(float64); ok {
+			// draft 04 gives exclusiveMinimum as a boolean modifier of minimum
+			if exclusive, _ := s["exclusiveMinimum"].(bool); exclusive && v <= min {
+				violate("must be greater than %v", min)
+			} else if v < min {
+				violate("must be greater than or equal to %v", min)
+			}
+		}
+		if max, ok := s["maximum"].(float64); ok {
+			if exclusive, _ := s["exclusiveMaximum"].(bool); exclusive && v >= max {
+				violate("must be less than %v", max)
+			} else if v > max {
+				violate("must be less than or equal to %v", max)
+			}
+		}
+		if min, ok := s["exclusiveMinimum"].(float64); ok && v <= min {
+			violate("must be greater than %v", min)
+		}
+		if max, ok := s["exclusiveMaximum"].(float64); ok && v >= max {
+			violate("must be less than %v", max)
+		}
+	}
+	return violations
+}
+
+func jsonSchemaValidateObject(s map[string]interface{}, v map[string]interface{}, path string) []string {
+	violations := make([]string, 0)
+	if required, ok := s["required"].([]interface{}); ok {
+		for _, r := range required {
+			name, _ := r.(string)
+			if _, ok := v[name]; !ok {
+				violations = append(violations, fmt.Sprintf("%s: property %q is required", path, name))
+			}
+		}
+	}
+	properties, _ := s["properties"].(map[string]interface{})
+	names := make([]string, 0, len(v))
+	for name := range v {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		propPath := fmt.Sprintf("%s.%s", path, name)
+		if propSchema, ok := properties[name].(map[string]interface{}); ok {
+			violations = append(violations, jsonSchemaValidate(propSchema, v[name], propPath)...)
+			continue
+		}
+		switch additional := s["additionalProperties"].(type) {
+		case bool:
+			if !additional {
+				violations = append(violations, fmt.Sprintf("%s: property is not allowed%s", propPath, jsonSchemaSuggest(name, properties)))
+			}
+		case map[string]interface{}:
+			violations = append(violations, jsonSchemaValidate(additional, v[name], propPath)...)
+		}
+	}
+	return violations
+}
+
+// jsonSchemaSuggest - suggest a known property when an unknown one differs only by case or separator
+func jsonSchemaSuggest(name string, properties map[string]interface{}) string {
+	simplify := func(s string) string {
+		return strings.NewReplacer("_", "", "-", "").Replace(strings.ToLower(s))
+	}
+	for known := range properties {
+		if simplify(known) == simplify(name) {
+			return fmt.Sprintf(", did you mean %q?", known)
+		}
+	}
+	return ""
+}
+
+func jsonSchemaTypes(t interface{}) []string {
+	switch v := t.(type) {
+	case string:
+		return []string{v}
+	case []interface{}:
+		types := make([]string, 0, len(v))
+		for _, e := range v {
+			if s, ok := e.(string); ok {
+				types = append(types, s)
+			}
+		}
+		return types
+	}
+	return nil
+}
+
+func jsonSchemaIsType(value interface{}, t string) bool {
+	if t == "integer" {
+		f, ok := value.(float64)
+		return ok && f == math.Trunc(f)
+	}
+	return jsonSchemaTypeOf(value) == t || (t == "number" && jsonSchemaTypeOf(value) == "integer")
+}
+
+func jsonSchemaTypeOf(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case float64:
+		if v == math.Trunc(v) {
+			return "integer"
+		}
+		return "number"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	}
+	return fmt.Sprintf("%T", value)
+}
@@ -0,0 +1,113 @@
+package cloudfoundry
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+const paramsSchemaTest = `{
+  "$schema": "http://json-schema.org/draft-04/schema#",
+  "type": "object",
+  "additionalProperties": false,
+  "required": ["size"],
+  "properties": {
+    "size": { "type": "string", "enum": ["small", "large"] },
+    "replicas": { "type": "integer", "minimum": 1, "maximum": 5 },
+    "backup": {
+      "type": "object",
+      "properties": {
+        "schedule": { "type": "string", "pattern": "^[0-9*/, -]+$" }
+      }
+    },
+    "users": {
+      "type": "array",
+      "maxItems": 2,
+      "items": { "type": "string", "minLength": 3 }
+    }
+  }
+}`
+
+func TestValidateParamsSchema(t *testing.T) {
+	var paramsSchema map[string]interface{}
+	if err := json.Unmarshal([]byte(paramsSchemaTest), &paramsSchema); err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		params     string
+		violations []string
+	}{
+		{`{"size": "small", "replicas": 3, "backup": {"schedule": "0 2 * * *"}, "users": ["admin"]}`, nil},
+		{`{"replicas": 3}`, []string{`json_params: property "size" is required`}},
+		{`{"size": "medium"}`, []string{`json_params.size: must be one of ["small","large"]`}},
+		{`{"size": "small", "replicas": 2.5}`, []string{`json_params.replicas: must be of type integer but is number`}},
+		{`{"size": "small", "replicas": 6}`, []string{`json_params.replicas: must be less than or equal to 5`}},
+		{`{"size": "small", "Replicas": 2}`, []string{`json_params.Replicas: property is not allowed, did you mean "replicas"?`}},
+		{`{"size": "small", "backup": {"schedule": "daily"}}`, []string{`json_params.backup.schedule: must match pattern`}},
+		{`{"size": "small", "users": ["admin", "me", "ops"]}`, []string{
+			`json_params.users: must have at most 2 items`,
+			`json_params.users[1]: must be at least 3 characters long`,
+		}},
+	}
+	for _, c := range cases {
+		err := validateParamsSchema("json_params", c.params, paramsSchema)
+		if len(c.violations) == 0 {
+			if err != nil {
+				t.Errorf("params %s: unexpected error: %s", c.params, err)
+			}
+			continue
+		}
+		if err == nil {
+			t.Errorf("params %s: expected error but got none", c.params)
+			continue
+		}
+		for _, v := range c.violations {
+			if !strings.Contains(err.Error(), v) {
+				t.Errorf("params %s: expected error to contain %q but got: %s", c.params, v, err)
+			}
+		}
+	}
+
+	if err := validateParamsSchema("json_params", `{"any": "thing"}`, nil); err != nil {
+		t.Errorf("no schema published must not fail validation: %s", err)
+	}
+}
+
+func TestBindingParamsCustomizeDiff(t *testing.T) {
+	var paramsSchema map[string]interface{}
+	if err := json.Unmarshal([]byte(paramsSchemaTest), &paramsSchema); err != nil {
+		t.Fatal(err)
+	}
+	cc := newFakeCC()
+	defer cc.server.Close()
+	cc.bindingSchema = paramsSchema
+	session := cc.session()
+
+	cases := []struct {
+		config map[string]interface{}
+		err    string
+	}{
+		{map[string]interface{}{"params": map[string]interface{}{"size": "small"}}, ""},
+		{map[string]interface{}{"params": map[string]interface{}{"size": "huge"}}, "params.size"},
+		{map[string]interface{}{"params": map[string]interface{}{"size": "small", "unknown": "x"}}, "params.unknown"},
+		{map[string]interface{}{"params_json": `{"size":"huge"}`}, "params_json.size"},
+	}
+	for _, r := range []string{"cloudfoundry_service_key", "cloudfoundry_service_key_rotation"} {
+		res := Provider().ResourcesMap[r]
+		for _, c := range cases {
+			c.config["name"] = "key"
+			c.config["service_instance"] = "si-guid"
+			_, err := res.Diff(context.Background(), nil, terraform.NewResourceConfigRaw(c.config), session)
+			if c.err == "" && err != nil {
+				t.Fatalf("%s: expected params %v to be valid but found: %s", r, c.config, err)
+			}
+			if c.err != "" && (err == nil || !strings.Contains(err.Error(), c.err)) {
+				t.Fatalf("%s: expected error on %s for params %v but found: %v", r, c.err, c.config, err)
+			}
+		}
+	}
+}
@@ -0,0 +1,18 @@
+package cloudfoundry
+
+import "fmt"
+
+// validateRoleTarget - error when role is given on an entity of other kind than the one its type applies to
+func validateRoleTarget(roleType string, org string, space string) error {
+	kind, ok := roleTypes[roleType]
+	if !ok {
+		return nil
+	}
+	if kind == "org" && space != "" {
+		return fmt.Errorf("role %s applies to an org, org must be set instead of space", roleType)
+	}
+	if kind == "space" && org != "" {
+		return fmt.Errorf("role %s applies to a space, space must be set instead of org", roleType)
+	}
+	return nil
+}
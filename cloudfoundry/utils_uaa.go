@@ -0,0 +1,104 @@
+package cloudfoundry
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"strconv"
+
+	"code.cloudfoundry.org/cli/api/uaa"
+	"github.com/terraform-providers/terraform-provider-cloudfoundry/cloudfoundry/managers"
+)
+
+// uaaPageSize - number of resources asked on each page of a uaa list endpoint
+const uaaPageSize = 500
+
+// uaaZoneHeader - header to act in another identity zone than the one of ClientUAA
+const uaaZoneHeader = "X-Identity-Zone-Id"
+
+// uaaDo - Do a raw request on uaa api with ClientUAA credentials, in is sent as json body and response is decoded into out.
+// Update and delete requests are made without version check, terraform is the only source of truth for resources it manages.
+func uaaDo(session *managers.Session, method string, path string, in interface{}, out interface{}) error {
+	return uaaDoInZone(session, "", method, path, in, out)
+}
+
+// uaaDoInZone - same as uaaDo in given identity zone, zone of ClientUAA is used when zone is empty
+func uaaDoInZone(session *managers.Session, zone string, method string, path string, in interface{}, out interface{}) error {
+	client := session.RawClientUAA
+	if client == nil {
+		return fmt.Errorf("uaa client is not available, uaa_client_id and uaa_client_secret must be set in provider")
+	}
+	var b []byte
+	if in != nil {
+		var err error
+		b, err = json.Marshal(in)
+		if err != nil {
+			return err
+		}
+	}
+	req, err := client.NewRequest(method, path, b)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	if b != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if method == "PUT" || method == "PATCH" || method == "DELETE" {
+		req.Header.Set("If-Match", "*")
+	}
+	if zone != "" {
+		req.Header.Set(uaaZoneHeader, zone)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return uaa.RawHTTPStatusError{
+			StatusCode:  resp.StatusCode,
+			RawResponse: body,
+		}
+	}
+	if out != nil && len(body) > 0 {
+		return json.Unmarshal(body, out)
+	}
+	return nil
+}
+
+// uaaGetAll - Retrieve all pages of a uaa scim list endpoint, resources of each page are given to f
+func uaaGetAll(session *managers.Session, path string, query url.Values, f func(resources json.RawMessage) error) error {
+	if query == nil {
+		query = url.Values{}
+	}
+	query.Set("count", strconv.Itoa(uaaPageSize))
+	startIndex := 1
+	for {
+		query.Set("startIndex", strconv.Itoa(startIndex))
+		var page struct {
+			Resources    json.RawMessage `json:"resources"`
+			TotalResults int             `json:"totalResults"`
+			ItemsPerPage int             `json:"itemsPerPage"`
+		}
+		err := uaaDo(session, "GET", path+"?"+query.Encode(), nil, &page)
+		if err != nil {
+			return err
+		}
+		err = f(page.Resources)
+		if err != nil {
+			return err
+		}
+		startIndex += page.ItemsPerPage
+		if page.ItemsPerPage == 0 || startIndex > page.TotalResults {
+			return nil
+		}
+	}
+}
@@ -0,0 +1,255 @@
+package cloudfoundry
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"github.com/terraform-providers/terraform-provider-cloudfoundry/cloudfoundry/managers"
+)
+
+const (
+	uaaProviderLDAP = "ldap"
+	uaaProviderSAML = "saml"
+	uaaProviderOIDC = "oidc1.0"
+)
+
+// uaaIdentityProvider - identity provider as given by uaa api
+type uaaIdentityProvider struct {
+	ID             string                 `json:"id,omitempty"`
+	OriginKey      string                 `json:"originKey"`
+	Name           string                 `json:"name"`
+	Type           string                 `json:"type"`
+	Active         bool                   `json:"active"`
+	IdentityZoneID string                 `json:"identityZoneId,omitempty"`
+	Config         map[string]interface{} `json:"config"`
+}
+
+// identityProviderField - attribute of an identity provider resource stored in provider config under Key
+type identityProviderField struct {
+	Key    string
+	Schema *schema.Schema
+}
+
+// identityProviderCommonFields - config attributes shared by all types of identity provider
+func identityProviderCommonFields() map[string]identityProviderField {
+	return map[string]identityProviderField{
+		"attribute_mappings": {
+			Key: "attributeMappings",
+			Schema: &schema.Schema{
+				Type:        schema.TypeMap,
+				Optional:    true,
+				Description: "Map uaa user attributes (e.g. given_name, email, external_groups) to attributes of identity provider",
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+		},
+		"external_groups_whitelist": {
+			Key: "externalGroupsWhitelist",
+			Schema: &schema.Schema{
+				Type:        schema.TypeSet,
+				Optional:    true,
+				Description: "Groups of identity provider kept in user token, wildcards are allowed",
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Set:         schema.HashString,
+			},
+		},
+		"email_domain": {
+			Key: "emailDomain",
+			Schema: &schema.Schema{
+				Type:        schema.TypeSet,
+				Optional:    true,
+				Description: "Email domains of users authenticated by identity provider",
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Set:         schema.HashString,
+			},
+		},
+		"add_shadow_user_on_login": {
+			Key: "addShadowUserOnLogin",
+			Schema: &schema.Schema{
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  true,
+			},
+		},
+	}
+}
+
+// resourceUAAIdentityProvider - resource for one type of identity provider, fields are config attributes specific to this type.
+// When origin is given, it is the only origin key accepted by uaa for this type (e.g. ldap)
+func resourceUAAIdentityProvider(providerType string, origin string, fields map[string]identityProviderField) *schema.Resource {
+	for k, f := range identityProviderCommonFields() {
+		fields[k] = f
+	}
+	s := map[string]*schema.Schema{
+		"name": &schema.Schema{
+			Type:         schema.TypeString,
+			Required:     true,
+			ValidateFunc: validation.NoZeroValues,
+		},
+		"active": &schema.Schema{
+			Type:     schema.TypeBool,
+			Optional: true,
+			Default:  true,
+		},
+		"zone_id": &schema.Schema{
+			Type:        schema.TypeString,
+			Optional:    true,
+			ForceNew:    true,
+			Description: "Identity zone of provider, default to zone of uaa client used by provider",
+		},
+	}
+	if origin == "" {
+		s["origin_key"] = &schema.Schema{
+			Type:         schema.TypeString,
+			Required:     true,
+			ForceNew:     true,
+			Description:  "Origin of users authenticated by this provider",
+			ValidateFunc: validation.NoZeroValues,
+		}
+	}
+	for k, f := range fields {
+		s[k] = f.Schema
+	}
+
+	return &schema.Resource{
+		CreateContext: func(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+			session := meta.(*managers.Session)
+			var idp uaaIdentityProvider
+			err := uaaDoInZone(session, d.Get("zone_id").(string), "POST", "/identity-providers",
+				resourceDataToIdentityProvider(d, providerType, origin, fields), &idp)
+			if err != nil {
+				return diag.FromErr(err)
+			}
+			d.SetId(idp.ID)
+			return identityProviderRead(d, session, origin, fields)
+		},
+		ReadContext: func(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+			return identityProviderRead(d, meta.(*managers.Session), origin, fields)
+		},
+		UpdateContext: func(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+			session := meta.(*managers.Session)
+			idp := resourceDataToIdentityProvider(d, providerType, origin, fields)
+			idp.ID = d.Id()
+			err := uaaDoInZone(session, d.Get("zone_id").(string), "PUT", fmt.Sprintf("/identity-providers/%s", d.Id()), idp, nil)
+			if err != nil {
+				return diag.FromErr(err)
+			}
+			return identityProviderRead(d, session, origin, fields)
+		},
+		DeleteContext: func(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+			session := meta.(*managers.Session)
+			err := uaaDoInZone(session, d.Get("zone_id").(string), "DELETE", fmt.Sprintf("/identity-providers/%s", d.Id()), nil, nil)
+			if err != nil && !IsErrNotFound(err) {
+				return diag.FromErr(err)
+			}
+			return nil
+		},
+		Importer: &schema.ResourceImporter{
+			StateContext: resourceUAAIdentityProviderImport,
+		},
+		Schema: s,
+	}
+}
+
+// resourceUAAIdentityProviderImport - provider is imported by its id or by <zone id>/<provider id> in another zone
+func resourceUAAIdentityProviderImport(ctx context.Context, d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	if zone, id, err := parseID(d.Id()); err == nil {
+		d.SetId(id)
+		d.Set("zone_id", zone)
+	}
+	return []*schema.ResourceData{d}, nil
+}
+
+func resourceDataToIdentityProvider(d *schema.ResourceData, providerType string, origin string, fields map[string]identityProviderField) uaaIdentityProvider {
+	if origin == "" {
+		origin = d.Get("origin_key").(string)
+	}
+	config := make(map[string]interface{})
+	for k, f := range fields {
+		v := d.Get(k)
+		switch f.Schema.Type {
+		case schema.TypeString:
+			if v.(string) == "" {
+				continue
+			}
+		case schema.TypeSet:
+			v = v.(*schema.Set).List()
+		}
+		config[f.Key] = v
+	}
+	return uaaIdentityProvider{
+		OriginKey: origin,
+		Name:      d.Get("name").(string),
+		Type:      providerType,
+		Active:    d.Get("active").(bool),
+		Config:    config,
+	}
+}
+
+func identityProviderRead(d *schema.ResourceData, session *managers.Session, origin string, fields map[string]identityProviderField) diag.Diagnostics {
+	var idp uaaIdentityProvider
+	err := uaaDoInZone(session, d.Get("zone_id").(string), "GET", fmt.Sprintf("/identity-providers/%s?rawConfig=true", d.Id()), nil, &idp)
+	if err != nil {
+		if IsErrNotFound(err) {
+			d.SetId("")
+			return nil
+		}
+		return diag.FromErr(err)
+	}
+	d.Set("name", idp.Name)
+	d.Set("active", idp.Active)
+	if origin == "" {
+		d.Set("origin_key", idp.OriginKey)
+	}
+	for k, f := range fields {
+		v, ok := idp.Config[f.Key]
+		// secrets are never given back by uaa
+		if !ok && f.Schema.Sensitive {
+			continue
+		}
+		d.Set(k, identityProviderConfigValue(f.Schema.Type, v))
+	}
+	return nil
+}
+
+// identityProviderConfigValue - convert a value decoded from json config to value of attribute
+func identityProviderConfigValue(t schema.ValueType, v interface{}) interface{} {
+	switch t {
+	case schema.TypeInt:
+		if f, ok := v.(float64); ok {
+			return int(f)
+		}
+		return 0
+	case schema.TypeBool:
+		b, _ := v.(bool)
+		return b
+	case schema.TypeSet, schema.TypeList:
+		list, _ := v.([]interface{})
+		if list == nil {
+			return []interface{}{}
+		}
+		return list
+	case schema.TypeMap:
+		m := make(map[string]interface{})
+		values, _ := v.(map[string]interface{})
+		for k, value := range values {
+			// some mappings (e.g. external_groups) can be a list of attributes
+			if list, ok := value.([]interface{}); ok {
+				items := make([]string, len(list))
+				for i, item := range list {
+					items[i] = fmt.Sprint(item)
+				}
+				m[k] = strings.Join(items, ",")
+				continue
+			}
+			m[k] = fmt.Sprint(value)
+		}
+		return m
+	default:
+		s, _ := v.(string)
+		return s
+	}
+}
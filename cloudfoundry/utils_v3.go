@@ -0,0 +1,201 @@
+package cloudfoundry
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"strings"
+	"time"
+
+	"code.cloudfoundry.org/cli/api/cloudcontroller/ccerror"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/terraform-providers/terraform-provider-cloudfoundry/cloudfoundry/managers"
+)
+
+// v3Relationship - to-one relationship as given by cloud controller v3 api
+type v3Relationship struct {
+	Data *struct {
+		GUID string `json:"guid"`
+	} `json:"data"`
+}
+
+func newV3Relationship(guid string) *v3Relationship {
+	r := &v3Relationship{}
+	r.Data = &struct {
+		GUID string `json:"guid"`
+	}{GUID: guid}
+	return r
+}
+
+// GUID - guid of related resource, empty if relationship is not set
+func (r *v3Relationship) GUID() string {
+	if r == nil || r.Data == nil {
+		return ""
+	}
+	return r.Data.GUID
+}
+
+// v3Job - job created by cloud controller v3 api on asynchronous operation
+type v3Job struct {
+	GUID   string `json:"guid"`
+	State  string `json:"state"`
+	Errors []struct {
+		Code   int    `json:"code"`
+		Title  string `json:"title"`
+		Detail string `json:"detail"`
+	} `json:"errors"`
+	Warnings []struct {
+		Detail string `json:"detail"`
+	} `json:"warnings"`
+	Links map[string]struct {
+		Href string `json:"href"`
+	} `json:"links"`
+}
+
+// LinkGUID - guid at the end of a resource link of job (e.g. service_instances)
+func (j v3Job) LinkGUID(name string) string {
+	link, ok := j.Links[name]
+	if !ok || link.Href == "" {
+		return ""
+	}
+	parts := strings.Split(strings.TrimSuffix(link.Href, "/"), "/")
+	return parts[len(parts)-1]
+}
+
+func (j v3Job) Error() string {
+	details := make([]string, 0, len(j.Errors))
+	for _, e := range j.Errors {
+		details = append(details, e.Detail)
+	}
+	if len(details) == 0 {
+		return fmt.Sprintf("Job %s has failed", j.GUID)
+	}
+	return strings.Join(details, "\n")
+}
+
+// WarningsDiags - warnings of job as terraform diagnostics
+func (j v3Job) WarningsDiags() diag.Diagnostics {
+	var diags diag.Diagnostics
+	for _, w := range j.Warnings {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Warning,
+			Summary:  w.Detail,
+		})
+	}
+	return diags
+}
+
+// v3Do - Do a raw request on cloud controller v3 api, in is sent as json body and response is decoded into out.
+// It gives back path of job to poll when cloud controller answers with an asynchronous operation.
+func v3Do(session *managers.Session, method string, path string, in interface{}, out interface{}) (string, error) {
+	var b []byte
+	if in != nil {
+		var err error
+		b, err = json.Marshal(in)
+		if err != nil {
+			return "", err
+		}
+	}
+	client := session.RawClient
+	req, err := client.NewRequest(method, path, b)
+	if err != nil {
+		return "", err
+	}
+	if b != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return "", ccerror.RawHTTPStatusError{
+			StatusCode:  resp.StatusCode,
+			RawResponse: body,
+		}
+	}
+	if out != nil && len(body) > 0 {
+		err = json.Unmarshal(body, out)
+		if err != nil {
+			return "", err
+		}
+	}
+	if resp.StatusCode != 202 {
+		return "", nil
+	}
+	location, err := url.Parse(resp.Header.Get("Location"))
+	if err != nil {
+		return "", err
+	}
+	return location.RequestURI(), nil
+}
+
+// v3GetAll - Retrieve all pages of a cloud controller v3 list endpoint, resources of each page are given to f
+func v3GetAll(session *managers.Session, path string, f func(resources json.RawMessage) error) error {
+	for path != "" {
+		var page struct {
+			Pagination struct {
+				Next *struct {
+					Href string `json:"href"`
+				} `json:"next"`
+			} `json:"pagination"`
+			Resources json.RawMessage `json:"resources"`
+		}
+		_, err := v3Do(session, "GET", path, nil, &page)
+		if err != nil {
+			return err
+		}
+		err = f(page.Resources)
+		if err != nil {
+			return err
+		}
+		path = ""
+		if page.Pagination.Next != nil && page.Pagination.Next.Href != "" {
+			next, err := url.Parse(page.Pagination.Next.Href)
+			if err != nil {
+				return err
+			}
+			path = next.RequestURI()
+		}
+	}
+	return nil
+}
+
+// v3PollJob - Poll a cloud controller v3 job until it is complete, failed or timeout is reached.
+// Failed job is given back as error.
+func v3PollJob(ctx context.Context, session *managers.Session, jobPath string, timeout time.Duration) (v3Job, error) {
+	var job v3Job
+	stateConf := &resource.StateChangeConf{
+		Pending: []string{"PROCESSING", "POLLING"},
+		Target:  []string{"COMPLETE"},
+		Refresh: func() (interface{}, string, error) {
+			job = v3Job{}
+			_, err := v3Do(session, "GET", jobPath, nil, &job)
+			if err != nil {
+				return nil, "", err
+			}
+			if job.State == "FAILED" {
+				return job, job.State, job
+			}
+			return job, job.State, nil
+		},
+		Timeout:      timeout,
+		PollInterval: 5 * time.Second,
+		Delay:        2 * time.Second,
+	}
+	_, err := stateConf.WaitForStateContext(ctx)
+	if failedJob, ok := err.(v3Job); ok {
+		return failedJob, failedJob
+	}
+	return job, err
+}
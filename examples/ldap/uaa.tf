@@ -0,0 +1,56 @@
+#
+# Let uaa authenticate users against the test ldap server, groups
+# are mapped to scopes as configured by uaa groups below
+#
+
+resource "cloudfoundry_uaa_ldap_provider" "ldap" {
+  name          = "Example LDAP"
+  url           = "ldap://host.pcfdev.io:40389"
+  bind_user_dn  = "cn=admin,dc=example,dc=org"
+  bind_password = "admin"
+
+  user_search_base   = "dc=example,dc=org"
+  user_search_filter = "uid={0}"
+
+  group_profile       = "ldap/ldap-groups-map-to-scopes.xml"
+  group_search_base   = "dc=example,dc=org"
+  group_search_filter = "member={0}"
+
+  attribute_mappings = {
+    given_name  = "givenName"
+    family_name = "sn"
+  }
+}
+
+#
+# Give custom scopes pcf.read and pcf.admin to members of ldap groups
+# 'users' and 'admins'. These scopes grant nothing in cloud controller,
+# a group existing in uaa like cloud_controller.admin can't be created
+# again: import it first (terraform import cloudfoundry_uaa_group.<name> <guid>)
+# to map an ldap group to it
+#
+
+resource "cloudfoundry_uaa_group" "pcf-read" {
+  display_name = "pcf.read"
+  description  = "Read access given to all pcf users"
+
+  external_group {
+    external_group = "cn=users,ou=pcf,dc=example,dc=org"
+    origin         = "ldap"
+  }
+}
+
+resource "cloudfoundry_uaa_group" "pcf-admins" {
+  display_name = "pcf.admin"
+  description  = "Administrators of pcf"
+
+  external_group {
+    external_group = "cn=admins,ou=pcf,dc=example,dc=org"
+    origin         = "ldap"
+  }
+}
+
+resource "cloudfoundry_uaa_group_membership" "pcf-read" {
+  group = "${cloudfoundry_uaa_group.pcf-read.id}"
+  users = ["${cloudfoundry_user.pcf-users.*.id}"]
+}